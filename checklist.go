@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// taskItemRegexp matches a Github task list item, e.g. "- [x] Add tests".
+var taskItemRegexp = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*?)\s*$`)
+
+type checklistItem struct {
+	text    string
+	checked bool
+}
+
+// parseChecklist returns the task list items of a markdown document in
+// order, skipping any inside fenced code blocks.
+func parseChecklist(markdown string) []checklistItem {
+	var items []checklistItem
+	fenced := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+
+		match := taskItemRegexp.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+		items = append(items, checklistItem{
+			text:    match[2],
+			checked: match[1] != " ",
+		})
+	}
+	return items
+}
+
+// checklistCondition tests the task list in the pull request description.
+// Items are selected by regexps tested against their text.
+type checklistCondition struct {
+	// Checked matches when every pattern matches a checked item.
+	Checked []string
+	// Unchecked matches when any pattern matches an unchecked item.
+	Unchecked []string
+	// Complete tests that the description has items and all are checked.
+	Complete *bool
+}
+
+func (c checklistCondition) matches(items []checklistItem) (bool, error) {
+	for _, pattern := range c.Checked {
+		found, err := hasChecklistItem(items, pattern, true)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if len(c.Unchecked) > 0 {
+		found := false
+		for _, pattern := range c.Unchecked {
+			var err error
+			found, err = hasChecklistItem(items, pattern, false)
+			if err != nil {
+				return false, err
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if c.Complete != nil {
+		complete := len(items) > 0
+		for _, item := range items {
+			complete = complete && item.checked
+		}
+		if *c.Complete != complete {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// hasChecklistItem reports whether an item matching pattern has the checked state.
+func hasChecklistItem(items []checklistItem, pattern string, checked bool) (bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, fmt.Errorf("failed to compile checklist regexp: %w", err)
+	}
+	for _, item := range items {
+		if item.checked == checked && re.MatchString(item.text) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
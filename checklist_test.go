@@ -0,0 +1,105 @@
+package main
+
+import "testing"
+
+const templateBody = `## Description
+Widgets now spin.
+
+## Checklist
+- [x] Breaking change
+- [ ] I have added tests
+* [X] I have updated the docs
+1. [ ] Migration reviewed
+- [] Not a task
+- [x]missing space
+
+` + "```" + `
+- [ ] Example in a code block
+` + "```"
+
+func TestParseChecklist(t *testing.T) {
+	expected := []checklistItem{
+		{text: "Breaking change", checked: true},
+		{text: "I have added tests", checked: false},
+		{text: "I have updated the docs", checked: true},
+		{text: "Migration reviewed", checked: false},
+	}
+
+	actual := parseChecklist(templateBody)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected items: %v, got: %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected item %d: %v, got: %v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestChecklistConditionMatches(t *testing.T) {
+	trueCheck := true
+	falseCheck := false
+	items := parseChecklist(templateBody)
+
+	tests := []struct {
+		name      string
+		condition checklistCondition
+		items     []checklistItem
+		expected  bool
+	}{
+		{
+			name:      "Checked",
+			condition: checklistCondition{Checked: []string{"^Breaking change$"}},
+			items:     items,
+			expected:  true,
+		},
+		{
+			name:      "Checked Every Pattern",
+			condition: checklistCondition{Checked: []string{"^Breaking change$", "tests"}},
+			items:     items,
+			expected:  false,
+		},
+		{
+			name:      "Unchecked Any Pattern",
+			condition: checklistCondition{Unchecked: []string{"docs", "tests"}},
+			items:     items,
+			expected:  true,
+		},
+		{
+			name:      "Unchecked None",
+			condition: checklistCondition{Unchecked: []string{"docs", "Breaking"}},
+			items:     items,
+			expected:  false,
+		},
+		{
+			name:      "Incomplete",
+			condition: checklistCondition{Complete: &falseCheck},
+			items:     items,
+			expected:  true,
+		},
+		{
+			name:      "Complete",
+			condition: checklistCondition{Complete: &trueCheck},
+			items:     []checklistItem{{text: "Done", checked: true}},
+			expected:  true,
+		},
+		{
+			name:      "Complete Without Items",
+			condition: checklistCondition{Complete: &trueCheck},
+			items:     nil,
+			expected:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.condition.matches(tc.items)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// checksMatch reports whether the head commit's checks have the conclusions
+// the conditions expect. Conclusions are compared case insensitively.
+func (c labelConditions) checksMatch(checks map[string]string) bool {
+	for name, expected := range c.Checks {
+		conclusion, ok := checks[name]
+		if !ok || !strings.EqualFold(conclusion, expected) {
+			return false
+		}
+	}
+
+	if c.ChecksAll != "" {
+		if len(checks) == 0 {
+			return false
+		}
+		for _, conclusion := range checks {
+			if !strings.EqualFold(conclusion, c.ChecksAll) {
+				return false
+			}
+		}
+	}
+
+	if c.ChecksAny != "" {
+		found := false
+		for _, conclusion := range checks {
+			found = found || strings.EqualFold(conclusion, c.ChecksAny)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// usesChecks reports whether any rule has a checks condition.
+func (c labelerConfig) usesChecks() bool {
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return len(conditions.Checks) > 0 || conditions.ChecksAll != "" || conditions.ChecksAny != ""
+	})
+}
+
+// ignoredChecks returns the glob patterns of the checks left out of the checks
+// conditions: those of ignore_checks, and the job running the labeler, which
+// is still in progress while it evaluates the checks. The job's check run is
+// named after its id unless the workflow gives the job a name, which has to
+// be listed in ignore_checks.
+func (c labelerConfig) ignoredChecks() []string {
+	ignored := append([]string{}, c.IgnoreChecks...)
+	if job := os.Getenv("GITHUB_JOB"); job != "" {
+		ignored = append(ignored, job)
+	}
+	return ignored
+}
+
+// loadChecks maps the name of each check run and commit status context on
+// the ref to its conclusion. Check runs take precedence over statuses, and
+// checks matching the ignored globs are left out.
+func loadChecks(client checksLister, ref string, ignored []string) (map[string]string, error) {
+	statuses, err := client.CombinedStatus(ref)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := client.ListCheckRuns(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make(map[string]string, len(statuses)+len(runs))
+	for _, result := range append(statuses, runs...) {
+		ignore, err := matchGlobs(ignored, result.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ignore {
+			checks[result.Name] = result.Conclusion
+		}
+	}
+	return checks, nil
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+
+	"github.com/MTIConnect/labeler-action/github"
+)
+
+// codeownersPaths are the locations Github reads a CODEOWNERS file from, in
+// order of precedence.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeowners are the rules of a CODEOWNERS file, in file order.
+type codeowners []codeownersRule
+
+type codeownersRule struct {
+	pattern string
+	// owners are the "@user" and "@org/team" owners of the rule. Email
+	// owners can't be matched to reviewers, so they're skipped.
+	owners []string
+}
+
+// parseCodeowners reads the rules of a CODEOWNERS file.
+func parseCodeowners(data []byte) codeowners {
+	var rules codeowners
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule := codeownersRule{pattern: fields[0]}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "@") {
+				rule.owners = append(rule.owners, owner)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// rule returns the last rule matching the path, as later rules take
+// precedence, or nil when no rule matches.
+func (c codeowners) rule(path string) *codeownersRule {
+	for i := len(c) - 1; i >= 0; i-- {
+		if matchCodeownersPattern(c[i].pattern, path) {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// matchCodeownersPattern matches a file path the way Github matches
+// CODEOWNERS patterns. Patterns follow gitignore rules: a pattern without a
+// leading or inner slash matches at any depth, and a pattern naming a
+// directory matches every file within it. Patterns ending in a wildcard,
+// like "docs/*", only match files directly in the directory.
+func matchCodeownersPattern(pattern, path string) bool {
+	directoryOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	if !strings.HasPrefix(pattern, "/") && !strings.Contains(trimmed, "/") {
+		trimmed = "**/" + trimmed
+	}
+
+	segments := strings.Split(path, "/")
+	last := trimmed[strings.LastIndex(trimmed, "/")+1:]
+	matchesDirectories := !strings.ContainsAny(last, "*?[") || last == "**"
+	for i := len(segments); i > 0; i-- {
+		if i == len(segments) && directoryOnly {
+			continue
+		}
+		if i < len(segments) && !matchesDirectories {
+			break
+		}
+		// Invalid patterns never match.
+		if matched, _ := matchGlob(trimmed, strings.Join(segments[:i], "/")); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// usesCodeownerApproved reports whether any rule has a codeowner_approved condition.
+func (c labelerConfig) usesCodeownerApproved() bool {
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return conditions.CodeownerApproved != nil
+	})
+}
+
+// loadCodeowners reads the CODEOWNERS file of the repository. Repositories
+// without the file have no rules.
+func loadCodeowners(client fileDownloader) (codeowners, error) {
+	for _, path := range codeownersPaths {
+		data, err := client.DownloadFileFromDefaultBranch(path)
+		if errors.Is(err, github.ErrFileNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parseCodeowners(data), nil
+	}
+	return nil, nil
+}
+
+// owners returns the owners of the changed files, in order of appearance.
+func (c codeowners) owners(files []changedFile) []string {
+	var owners []string
+	for _, file := range files {
+		rule := c.rule(file.path)
+		if rule == nil {
+			continue
+		}
+		for _, owner := range rule.owners {
+			if !contains(owners, owner) {
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// ownerSubpattern matches the team slugs and user logins rendered as {{.owner}}.
+const ownerSubpattern = `[\w.-]+`
+
+// ownersRule labels pull requests with the owners of their changed files.
+type ownersRule struct {
+	// Label is a template rendered for each owner with its team slug or user
+	// login as {{.owner}}, e.g. "owner/{{.owner}}".
+	Label     string
+	CrossTeam *crossTeamRule `yaml:"cross_team"`
+}
+
+// crossTeamRule applies a label when the changed files have more owners than
+// MoreThan, or more than one owner when it's unset.
+type crossTeamRule struct {
+	Label    string
+	MoreThan *int `yaml:"more_than"`
+}
+
+// validate reports a cross_team rule without a label.
+func (r ownersRule) validate() error {
+	if r.CrossTeam != nil && r.CrossTeam.Label == "" {
+		return errors.New("owners.cross_team needs a label")
+	}
+	return nil
+}
+
+// apply replaces the owner labels with those of the current owners.
+func (r ownersRule) apply(labels []string, owners []string) ([]string, error) {
+	if r.Label != "" {
+		tmpl, err := template.New(r.Label).Option("missingkey=error").Parse(r.Label)
+		if err != nil {
+			return nil, err
+		}
+		stale, err := labelTemplateRegexp(r.Label, map[string]string{"owner": ownerSubpattern})
+		if err != nil {
+			return nil, err
+		}
+
+		var current []string
+		for _, owner := range owners {
+			name := strings.TrimPrefix(owner, "@")
+			name = name[strings.LastIndex(name, "/")+1:]
+
+			var rendered strings.Builder
+			if err := tmpl.Execute(&rendered, map[string]string{"owner": name}); err != nil {
+				return nil, err
+			}
+			current = append(current, rendered.String())
+		}
+
+		n := 0
+		for _, label := range labels {
+			if !stale.MatchString(label) || contains(current, label) {
+				labels[n] = label
+				n++
+			}
+		}
+		labels = labels[:n]
+		for _, label := range current {
+			labels = addLabel(labels, label)
+		}
+	}
+
+	if r.CrossTeam != nil && r.CrossTeam.Label != "" {
+		threshold := 1
+		if r.CrossTeam.MoreThan != nil {
+			threshold = *r.CrossTeam.MoreThan
+		}
+		if len(owners) > threshold {
+			labels = addLabel(labels, r.CrossTeam.Label)
+		} else {
+			labels = removeLabel(labels, r.CrossTeam.Label)
+		}
+	}
+
+	return labels, nil
+}
+
+// codeownersApproved reports whether every rule owning a changed file has an
+// approval from one of its owners, either directly or through team membership.
+func codeownersApproved(client teamMemberChecker, rules codeowners, files []changedFile, approvers []string) (bool, error) {
+	for _, file := range files {
+		rule := rules.rule(file.path)
+		if rule == nil || len(rule.owners) == 0 {
+			continue
+		}
+
+		approved, err := ownerApproved(client, rule.owners, approvers)
+		if err != nil {
+			return false, err
+		}
+		if !approved {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func ownerApproved(client teamMemberChecker, owners, approvers []string) (bool, error) {
+	for _, owner := range owners {
+		owner = strings.TrimPrefix(owner, "@")
+		for _, approver := range approvers {
+			if !strings.Contains(owner, "/") {
+				if strings.EqualFold(owner, approver) {
+					return true, nil
+				}
+				continue
+			}
+
+			member, err := client.IsTeamMember(owner, approver)
+			if err != nil {
+				return false, err
+			}
+			if member {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
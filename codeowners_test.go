@@ -0,0 +1,275 @@
+package main
+
+import "testing"
+
+func TestMatchCodeownersPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "*", path: "src/app/main.go", expected: true},
+		{pattern: "*.js", path: "web/static/app.js", expected: true},
+		{pattern: "*.js", path: "web/static/app.ts", expected: false},
+		{pattern: "/build/logs/", path: "build/logs/2020/today.log", expected: true},
+		{pattern: "/build/logs/", path: "src/build/logs/today.log", expected: false},
+		{pattern: "docs/*", path: "docs/getting-started.md", expected: true},
+		{pattern: "docs/*", path: "docs/build-app/troubleshooting.md", expected: false},
+		{pattern: "apps/", path: "apps/main.go", expected: true},
+		{pattern: "apps/", path: "src/apps/web/main.go", expected: true},
+		{pattern: "apps/", path: "apps", expected: false},
+		{pattern: "/docs/", path: "docs/guide/index.md", expected: true},
+		{pattern: "/docs/", path: "src/docs/index.md", expected: false},
+		{pattern: "/scripts", path: "scripts/deploy.sh", expected: true},
+		{pattern: "**/logs", path: "deeply/nested/logs/today.log", expected: true},
+		{pattern: "db/migrations/**", path: "db/migrations/2020/0001.sql", expected: true},
+		{pattern: "README.md", path: "docs/README.md", expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern+" "+tc.path, func(t *testing.T) {
+			actual := matchCodeownersPattern(tc.pattern, tc.path)
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCodeownersRule(t *testing.T) {
+	rules := parseCodeowners([]byte(`
+# Default owners.
+*       @MTIConnect/platform
+
+*.sql   @MTIConnect/dba dba@example.com # Inline comment.
+/docs/  @octocat
+/docs/generated/
+`))
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "main.go", expected: []string{"@MTIConnect/platform"}},
+		{path: "db/migrations/0001.sql", expected: []string{"@MTIConnect/dba"}},
+		{path: "docs/guide.md", expected: []string{"@octocat"}},
+		{path: "docs/generated/api.md", expected: []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			rule := rules.rule(tc.path)
+			if rule == nil {
+				t.Fatalf("expected rule, got: nil")
+			}
+			assertStringSlicesEqual(t, tc.expected, rule.owners)
+		})
+	}
+}
+
+func TestCodeownersApproved(t *testing.T) {
+	client := fakePRClient{
+		teams: map[string][]string{
+			"MTIConnect/dba": {"dbadmin"},
+		},
+	}
+	rules := parseCodeowners([]byte(`
+*.sql   @MTIConnect/dba
+/docs/  @octocat @hubot
+/docs/generated/
+`))
+
+	tests := []struct {
+		name      string
+		files     []changedFile
+		approvers []string
+		expected  bool
+	}{
+		{
+			name:      "No Owned Files",
+			files:     []changedFile{{path: "main.go"}, {path: "docs/generated/api.md"}},
+			approvers: nil,
+			expected:  true,
+		},
+		{
+			name:      "User Owner Approved",
+			files:     []changedFile{{path: "docs/guide.md"}},
+			approvers: []string{"Hubot"},
+			expected:  true,
+		},
+		{
+			name:      "Team Owner Approved",
+			files:     []changedFile{{path: "db/0001.sql"}, {path: "docs/guide.md"}},
+			approvers: []string{"octocat", "dbadmin"},
+			expected:  true,
+		},
+		{
+			name:      "Team Owner Missing",
+			files:     []changedFile{{path: "db/0001.sql"}, {path: "docs/guide.md"}},
+			approvers: []string{"octocat"},
+			expected:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := codeownersApproved(client, rules, tc.files, tc.approvers)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLoadCodeowners(t *testing.T) {
+	client := fakePRClient{
+		content: map[string][]byte{
+			"CODEOWNERS":      []byte("* @root-owner"),
+			"docs/CODEOWNERS": []byte("* @docs-owner"),
+		},
+	}
+
+	rules, err := loadCodeowners(client)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(rules) != 1 || rules[0].owners[0] != "@root-owner" {
+		t.Fatalf("expected root CODEOWNERS rules, got: %v", rules)
+	}
+
+	rules, err = loadCodeowners(fakePRClient{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if rules != nil {
+		t.Fatalf("expected no rules, got: %v", rules)
+	}
+}
+
+func TestCodeownersOwners(t *testing.T) {
+	rules := parseCodeowners([]byte(`
+*         @MTIConnect/platform
+*.sql     @MTIConnect/dba @octocat
+/billing/ @MTIConnect/payments
+`))
+	files := []changedFile{
+		{path: "billing/invoice.go"},
+		{path: "billing/schema.sql"},
+		{path: "main.go"},
+		{path: "db/0001.sql"},
+	}
+
+	// The billing directory entry is last, so it owns billing SQL files.
+	expected := []string{"@MTIConnect/payments", "@MTIConnect/platform", "@MTIConnect/dba", "@octocat"}
+	assertStringSlicesEqual(t, expected, rules.owners(files))
+}
+
+func TestOwnersRuleApply(t *testing.T) {
+	moreThan := 2
+	rule := ownersRule{
+		Label:     "owner/{{.owner}}",
+		CrossTeam: &crossTeamRule{Label: "cross-team", MoreThan: &moreThan},
+	}
+
+	tests := []struct {
+		name     string
+		labels   []string
+		owners   []string
+		expected []string
+	}{
+		{
+			name:     "No Owners",
+			labels:   []string{"Bug", "owner/payments", "cross-team"},
+			owners:   nil,
+			expected: []string{"Bug"},
+		},
+		{
+			name:     "Replaces Stale Owners",
+			labels:   []string{"owner/payments", "Bug", "owner/dba"},
+			owners:   []string{"@MTIConnect/platform", "@MTIConnect/payments"},
+			expected: []string{"owner/payments", "Bug", "owner/platform"},
+		},
+		{
+			name:     "Cross Team",
+			labels:   []string{"Bug"},
+			owners:   []string{"@MTIConnect/platform", "@MTIConnect/payments", "@octocat"},
+			expected: []string{"Bug", "owner/platform", "owner/payments", "owner/octocat", "cross-team"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := rule.apply(tc.labels, tc.owners)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqual(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCrossTeamRuleThreshold(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name     string
+		moreThan *int
+		owners   []string
+		expected []string
+	}{
+		{
+			name:     "Default One Owner",
+			owners:   []string{"@MTIConnect/platform"},
+			expected: []string{},
+		},
+		{
+			name:     "Default Two Owners",
+			owners:   []string{"@MTIConnect/platform", "@octocat"},
+			expected: []string{"cross-team"},
+		},
+		{
+			name:     "Explicit Zero",
+			moreThan: &zero,
+			owners:   []string{"@MTIConnect/platform"},
+			expected: []string{"cross-team"},
+		},
+		{
+			name:     "Explicit Zero Without Owners",
+			moreThan: &zero,
+			owners:   nil,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := ownersRule{CrossTeam: &crossTeamRule{Label: "cross-team", MoreThan: tc.moreThan}}
+			actual, err := rule.apply([]string{}, tc.owners)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqual(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestOwnersRuleValidate(t *testing.T) {
+	if err := (ownersRule{Label: "owner/{{.owner}}"}).validate(); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	}
+	if err := (ownersRule{CrossTeam: &crossTeamRule{Label: "cross-team"}}).validate(); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	}
+	if err := (ownersRule{CrossTeam: &crossTeamRule{}}).validate(); err == nil {
+		t.Errorf("expected error for cross_team without label, got: nil")
+	}
+
+	// Without a label, the rule never adds an empty one.
+	labels, err := ownersRule{Label: "owner/{{.owner}}", CrossTeam: &crossTeamRule{}}.apply(nil, []string{"@a", "@b"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	assertStringSlicesEqual(t, []string{"owner/a", "owner/b"}, labels)
+}
@@ -0,0 +1,103 @@
+package main
+
+import "fmt"
+
+// conditionPathError is an error evaluating a condition, located by its YAML
+// path within the config, e.g. "Bug.any[1].title".
+type conditionPathError struct {
+	path string
+	err  error
+}
+
+func (e *conditionPathError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+func (e *conditionPathError) Unwrap() error {
+	return e.err
+}
+
+// withConditionPath prefixes the path of err with the segment.
+func withConditionPath(segment string, err error) error {
+	if pathErr, ok := err.(*conditionPathError); ok {
+		return &conditionPathError{path: segment + "." + pathErr.path, err: pathErr.err}
+	}
+	return &conditionPathError{path: segment, err: err}
+}
+
+// combinatorsMatch evaluates the nested all, any and not blocks. Captures
+// are kept from the blocks that matched, and never from a not block.
+func (c labelConditions) combinatorsMatch(state prState, captures map[string]string) (bool, error) {
+	for i, nested := range c.All {
+		matched, err := nested.matches(state, captures)
+		if err != nil {
+			return false, withConditionPath(fmt.Sprintf("all[%d]", i), err)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if len(c.Any) > 0 {
+		found := false
+		for i, nested := range c.Any {
+			nestedCaptures := make(map[string]string)
+			matched, err := nested.matches(state, nestedCaptures)
+			if err != nil {
+				return false, withConditionPath(fmt.Sprintf("any[%d]", i), err)
+			}
+			if matched {
+				for name, value := range nestedCaptures {
+					captures[name] = value
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if c.Not != nil {
+		matched, err := c.Not.matches(state, make(map[string]string))
+		if err != nil {
+			return false, withConditionPath("not", err)
+		}
+		if matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// walk calls fn with the conditions and each block nested within them until
+// fn returns true, and reports whether it did.
+func (c labelConditions) walk(fn func(labelConditions) bool) bool {
+	if fn(c) {
+		return true
+	}
+	for _, nested := range c.All {
+		if nested.walk(fn) {
+			return true
+		}
+	}
+	for _, nested := range c.Any {
+		if nested.walk(fn) {
+			return true
+		}
+	}
+	return c.Not != nil && c.Not.walk(fn)
+}
+
+// anyConditions reports whether fn returns true for any condition block of
+// any rule, including nested blocks.
+func (c labelerConfig) anyConditions(fn func(labelConditions) bool) bool {
+	for _, conditions := range c.Labels {
+		if conditions.walk(fn) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLabelsForPRStateCombinators(t *testing.T) {
+	var config labelerConfig
+	err := yaml.Unmarshal([]byte(`
+Bug:
+  any:
+    - branch_name: "^bug/"
+    - title: "^Fix"
+Ready:
+  all:
+    - approved: true
+    - not:
+        changes_requested: true
+  not:
+    draft: true
+Ticket {{.ticket}}:
+  any:
+    - title: "^(?P<ticket>[A-Z]+-[0-9]+)"
+    - branch_name: "^feature/(?P<ticket>[A-Z]+-[0-9]+)"
+`), &config)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name:     "Bug Branch",
+			state:    prState{branchName: "bug/login", draft: true, approved: true},
+			expected: []string{"Bug"},
+		},
+		{
+			name:     "Fix Title",
+			state:    prState{title: "Fix login", approved: true, changesRequested: true},
+			expected: []string{"Bug"},
+		},
+		{
+			name:     "Ready",
+			state:    prState{labels: []string{"Bug"}, title: "Add login", approved: true},
+			expected: []string{"Ready"},
+		},
+		{
+			name:     "Ticket From Branch",
+			state:    prState{title: "Add login", branchName: "feature/WEB-12-login"},
+			expected: []string{"Ticket WEB-12"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLabelsForPRStateCombinatorErrorPath(t *testing.T) {
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Bug": {
+			Any: []labelConditions{
+				{BranchName: "^bug/"},
+				{Not: &labelConditions{Title: "(unclosed"}},
+			},
+		},
+	}}
+
+	_, err := config.labelsForPRState(prState{branchName: "feature"})
+	if err == nil {
+		t.Fatalf("expected error for invalid regexp, got: nil")
+	}
+	if !strings.Contains(err.Error(), "Bug.any[1].not.title: ") {
+		t.Fatalf("expected error with path %q, got: %v", "Bug.any[1].not.title", err)
+	}
+}
+
+func TestConfigWalkersNested(t *testing.T) {
+	falseCheck := false
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Platform": {
+			Any: []labelConditions{
+				{AuthorTeam: []string{"platform"}},
+				{Not: &labelConditions{AuthorTeam: []string{"sre"}, Mergeable: &falseCheck}},
+			},
+		},
+		"CI": {
+			All: []labelConditions{{ChecksAny: "failure"}},
+		},
+	}}
+
+	assertStringSlicesEqualUnordered(t, []string{"platform", "sre"}, config.authorTeams())
+	if !config.usesChecks() || !config.usesMergeable() {
+		t.Errorf("expected nested checks and mergeable conditions to be found")
+	}
+	if config.usesBehindBase() || config.usesCodeownerApproved() {
+		t.Errorf("unexpected conditions found")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// comparison tests a number against a fixed value, written in config as an
+// operator followed by the value, e.g. ">500" or "<=3". A bare value tests
+// for equality.
+type comparison struct {
+	operator string
+	value    int
+}
+
+// Operators are listed so that two character operators are tried first.
+var comparisonOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+func parseComparison(s string) (comparison, error) {
+	s = strings.TrimSpace(s)
+	operator := "=="
+	for _, op := range comparisonOperators {
+		if strings.HasPrefix(s, op) {
+			operator = op
+			s = strings.TrimSpace(s[len(op):])
+			break
+		}
+	}
+
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return comparison{}, fmt.Errorf("invalid comparison value %q", s)
+	}
+	return comparison{operator: operator, value: value}, nil
+}
+
+func (c *comparison) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := parseComparison(s)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*c = parsed
+	return nil
+}
+
+func (c comparison) matches(n int) bool {
+	switch c.operator {
+	case ">=":
+		return n >= c.value
+	case "<=":
+		return n <= c.value
+	case "!=":
+		return n != c.value
+	case ">":
+		return n > c.value
+	case "<":
+		return n < c.value
+	default:
+		return n == c.value
+	}
+}
+
+func (c comparison) String() string {
+	return c.operator + strconv.Itoa(c.value)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected comparison
+		err      bool
+	}{
+		{input: ">500", expected: comparison{operator: ">", value: 500}},
+		{input: "<= 3", expected: comparison{operator: "<=", value: 3}},
+		{input: ">=10", expected: comparison{operator: ">=", value: 10}},
+		{input: "!=0", expected: comparison{operator: "!=", value: 0}},
+		{input: "==2", expected: comparison{operator: "==", value: 2}},
+		{input: "7", expected: comparison{operator: "==", value: 7}},
+		{input: ">", err: true},
+		{input: "=>5", err: true},
+		{input: "lots", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := parseComparison(tc.input)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got: %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %v, got: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestComparisonMatches(t *testing.T) {
+	tests := []struct {
+		comparison string
+		value      int
+		expected   bool
+	}{
+		{comparison: ">500", value: 501, expected: true},
+		{comparison: ">500", value: 500, expected: false},
+		{comparison: ">=500", value: 500, expected: true},
+		{comparison: "<3", value: 3, expected: false},
+		{comparison: "<=3", value: 3, expected: true},
+		{comparison: "!=0", value: 0, expected: false},
+		{comparison: "0", value: 0, expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.comparison, func(t *testing.T) {
+			c, err := parseComparison(tc.comparison)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual := c.matches(tc.value); actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestComparisonUnmarshalYAML(t *testing.T) {
+	var conditions labelConditions
+	err := yaml.Unmarshal([]byte("additions: \">500\"\ncommits: 3\n"), &conditions)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if conditions.Additions == nil || *conditions.Additions != (comparison{operator: ">", value: 500}) {
+		t.Fatalf("unexpected additions condition: %v", conditions.Additions)
+	}
+	if conditions.Commits == nil || *conditions.Commits != (comparison{operator: "==", value: 3}) {
+		t.Fatalf("unexpected commits condition: %v", conditions.Commits)
+	}
+
+	err = yaml.Unmarshal([]byte("comments: \"many\"\n"), &conditions)
+	if err == nil {
+		t.Fatalf("expected error for invalid comparison, got: nil")
+	}
+}
+
+func TestLabelsForPRStateCounts(t *testing.T) {
+	trueCheck := true
+	huge := comparison{operator: ">", value: 500}
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Huge": {
+			Additions: &huge,
+		},
+		"WIP": {
+			Draft: &trueCheck,
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name: "Huge Draft",
+			state: prState{
+				labels:    []string{"Huge"},
+				draft:     true,
+				additions: 501,
+			},
+			expected: []string{"Huge", "WIP"},
+		},
+		{
+			name: "No Longer Huge Draft",
+			state: prState{
+				labels:    []string{"Huge", "WIP"},
+				draft:     true,
+				additions: 500,
+			},
+			expected: []string{"WIP"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
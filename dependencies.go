@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelReferences returns the labels referenced by requires_labels and
+// absent_labels conditions, including those of nested blocks.
+func (c labelConditions) labelReferences() []string {
+	var references []string
+	c.walk(func(conditions labelConditions) bool {
+		for _, label := range append(conditions.RequiresLabels, conditions.AbsentLabels...) {
+			if !contains(references, label) {
+				references = append(references, label)
+			}
+		}
+		return false
+	})
+	return references
+}
+
+// ruleApplies reports whether the rule can apply the label, either because
+// it's the rule's label or a label its template renders.
+func (c labelerConfig) ruleApplies(rule, label string) (bool, error) {
+	if !isLabelTemplate(rule) {
+		return rule == label, nil
+	}
+	subpatterns, err := c.Labels[rule].captureSubpatterns()
+	if err != nil {
+		return false, fmt.Errorf("failed to parse rule regexes: %w", withConditionPath(rule, err))
+	}
+	re, err := labelTemplateRegexp(rule, subpatterns)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse label template %q: %w", rule, err)
+	}
+	return re.MatchString(label), nil
+}
+
+// ruleOrder returns the labels of the rules in evaluation order, with every
+// rule after the rules applying labels it references, and after the rules
+// listed before it in its groups. Rules are otherwise ordered by label, so
+// evaluation is deterministic. Rules depending on each other in a cycle
+// can't be ordered.
+func (c labelerConfig) ruleOrder() ([]string, error) {
+	if err := c.validateGroups(); err != nil {
+		return nil, err
+	}
+
+	rules := make([]string, 0, len(c.Labels))
+	for rule := range c.Labels {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	dependencies := make(map[string][]string, len(rules))
+	for _, rule := range rules {
+		dependencies[rule] = c.groupPredecessors(rule)
+		for _, label := range c.Labels[rule].labelReferences() {
+			for _, other := range rules {
+				applies, err := c.ruleApplies(other, label)
+				if err != nil {
+					return nil, err
+				}
+				if applies {
+					dependencies[rule] = append(dependencies[rule], other)
+				}
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[string]int, len(rules))
+	order := make([]string, 0, len(rules))
+	var path []string
+	var visit func(rule string) error
+	visit = func(rule string) error {
+		switch states[rule] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for path[start] != rule {
+				start++
+			}
+			cycle := append(append([]string(nil), path[start:]...), rule)
+			return fmt.Errorf("rules depend on each other in a cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		states[rule] = visiting
+		path = append(path, rule)
+		for _, dependency := range dependencies[rule] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		states[rule] = visited
+		order = append(order, rule)
+		return nil
+	}
+
+	for _, rule := range rules {
+		if err := visit(rule); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
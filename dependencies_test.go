@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleOrder(t *testing.T) {
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Ready":          {AbsentLabels: []string{"do-not-merge"}, RequiresLabels: []string{"Approved"}},
+		"Approved":       {Any: []labelConditions{{RequiresLabels: []string{"team/platform"}}}},
+		"team/{{.team}}": {},
+		"Bug":            {},
+		"Hotfix":         {RequiresLabels: []string{"Bug"}},
+	}}
+
+	order, err := config.ruleOrder()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	assertStringSlicesEqual(t, []string{"team/{{.team}}", "Approved", "Bug", "Hotfix", "Ready"}, order)
+}
+
+func TestRuleOrderCycle(t *testing.T) {
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"A": {RequiresLabels: []string{"B"}},
+		"B": {Not: &labelConditions{AbsentLabels: []string{"C"}}},
+		"C": {RequiresLabels: []string{"A"}},
+		"D": {},
+	}}
+
+	_, err := config.ruleOrder()
+	if err == nil {
+		t.Fatalf("expected cycle error, got: nil")
+	}
+	if !strings.Contains(err.Error(), "A -> B -> C -> A") {
+		t.Fatalf("expected error naming the cycle, got: %v", err)
+	}
+}
+
+func TestLabelsForPRStateLabelReferences(t *testing.T) {
+	trueCheck := true
+	config := labelerConfig{
+		Size: &sizeRule{Buckets: map[string]int{"size/S": 0, "size/XL": 500}},
+		Labels: map[string]labelConditions{
+			"Bug": {
+				BranchName: "^bug/",
+			},
+			"Hotfix": {
+				BaseBranch:     "^release/",
+				RequiresLabels: []string{"Bug"},
+			},
+			"Ready To Merge": {
+				Approved:     &trueCheck,
+				AbsentLabels: []string{"do-not-merge", "Hotfix"},
+			},
+			"Needs Split": {
+				RequiresLabels: []string{"size/XL"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name: "Computed This Run",
+			state: prState{
+				branchName: "bug/login",
+				baseBranch: "release/1.0",
+				approved:   true,
+				files:      []changedFile{{path: "main.go", additions: 600}},
+			},
+			expected: []string{"Bug", "Hotfix", "size/XL", "Needs Split"},
+		},
+		{
+			name: "Already On PR",
+			state: prState{
+				labels:     []string{"do-not-merge", "Ready To Merge"},
+				branchName: "feature/login",
+				approved:   true,
+			},
+			expected: []string{"do-not-merge", "size/S"},
+		},
+		{
+			name: "Removed This Run",
+			state: prState{
+				labels:     []string{"Bug", "Hotfix"},
+				branchName: "feature/login",
+				baseBranch: "release/1.0",
+				approved:   true,
+			},
+			expected: []string{"Ready To Merge", "size/S"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Map iteration order varies, so repeat to catch order dependence.
+			for i := 0; i < 20; i++ {
+				actual, err := config.labelsForPRState(tc.state)
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+				assertStringSlicesEqualUnordered(t, tc.expected, actual)
+			}
+		})
+	}
+}
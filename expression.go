@@ -0,0 +1,516 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exprType is the type of an expression value.
+type exprType int
+
+const (
+	exprBool exprType = iota
+	exprInt
+	exprString
+	exprList
+)
+
+func (t exprType) String() string {
+	switch t {
+	case exprBool:
+		return "bool"
+	case exprInt:
+		return "int"
+	case exprString:
+		return "string"
+	default:
+		return "list"
+	}
+}
+
+// exprVariable is a variable expressions can use, read from the pull request state.
+type exprVariable struct {
+	typ   exprType
+	value func(prState) interface{}
+}
+
+// exprVariables are the variables available to expressions. Lists are lists
+// of strings.
+var exprVariables = map[string]exprVariable{
+	"draft":              {exprBool, func(s prState) interface{} { return s.draft }},
+	"title":              {exprString, func(s prState) interface{} { return s.title }},
+	"body":               {exprString, func(s prState) interface{} { return s.body }},
+	"branch_name":        {exprString, func(s prState) interface{} { return s.branchName }},
+	"base_branch":        {exprString, func(s prState) interface{} { return s.baseBranch }},
+	"author":             {exprString, func(s prState) interface{} { return s.author }},
+	"author_is_bot":      {exprBool, func(s prState) interface{} { return s.authorIsBot }},
+	"author_association": {exprString, func(s prState) interface{} { return s.authorAssociation }},
+	"labels":             {exprList, func(s prState) interface{} { return s.labels }},
+	"files":              {exprList, func(s prState) interface{} { return filePaths(s.files) }},
+	"additions":          {exprInt, func(s prState) interface{} { return s.additions }},
+	"deletions":          {exprInt, func(s prState) interface{} { return s.deletions }},
+	"changed_files":      {exprInt, func(s prState) interface{} { return s.changedFiles }},
+	"commits":            {exprInt, func(s prState) interface{} { return s.commits }},
+	"comments":           {exprInt, func(s prState) interface{} { return s.comments }},
+	"review_comments":    {exprInt, func(s prState) interface{} { return s.reviewComments }},
+	"approvals":          {exprInt, func(s prState) interface{} { return s.approvals }},
+	"approved":           {exprBool, func(s prState) interface{} { return s.approved }},
+	"changes_requests":   {exprInt, func(s prState) interface{} { return s.changesRequests }},
+	"changes_requested":  {exprBool, func(s prState) interface{} { return s.changesRequested }},
+	"review_requested": {exprBool, func(s prState) interface{} {
+		return len(s.requestedReviewers) > 0 || len(s.requestedTeams) > 0
+	}},
+	"requested_teams":    {exprList, func(s prState) interface{} { return s.requestedTeams }},
+	"unresolved_threads": {exprInt, func(s prState) interface{} { return s.unresolvedThreads }},
+	"mergeable":          {exprBool, func(s prState) interface{} { return s.mergeable }},
+	"behind_base":        {exprBool, func(s prState) interface{} { return s.behindBase }},
+	"linked_issue":       {exprBool, func(s prState) interface{} { return len(s.linkedIssues) > 0 }},
+}
+
+func filePaths(files []changedFile) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.path)
+	}
+	return paths
+}
+
+// exprError is an error at a byte offset of an expression's source.
+type exprError struct {
+	offset int
+	msg    string
+}
+
+func (e *exprError) Error() string {
+	return e.msg
+}
+
+// expression is a boolean expression over the pull request state, e.g.
+// `additions > 500 && author != "dependabot[bot]" && !draft`. Expressions
+// are parsed and type checked when the config is loaded, so evaluating them
+// can't fail.
+type expression struct {
+	source string
+	root   *exprNode
+}
+
+// exprNode is a node of a parsed expression. Literals keep their value,
+// variables their name in value, and operators their operands.
+type exprNode struct {
+	op     string
+	offset int
+	value  interface{}
+	typ    exprType
+	left   *exprNode
+	right  *exprNode
+	regexp *regexp.Regexp
+}
+
+func parseExpression(source string) (*expression, error) {
+	tokens, err := lexExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != exprEOF {
+		return nil, &exprError{tok.offset, fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	if err := root.check(); err != nil {
+		return nil, err
+	}
+	if root.typ != exprBool {
+		return nil, &exprError{root.offset, fmt.Sprintf("expression is %v, not bool", root.typ)}
+	}
+	return &expression{source: source, root: root}, nil
+}
+
+func (e *expression) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := parseExpression(s)
+	if exprErr, ok := err.(*exprError); ok {
+		line, column := exprPosition(node, s, exprErr.offset)
+		return fmt.Errorf("line %d, column %d: invalid expression: %w", line, column, err)
+	}
+	if err != nil {
+		return err
+	}
+	*e = *parsed
+	return nil
+}
+
+// exprPosition converts an offset within the expression into its line and
+// column in the config file. The indentation of block scalars isn't known,
+// so their columns are relative to the expression, and escape sequences in
+// quoted scalars shift the columns after them.
+func exprPosition(node *yaml.Node, source string, offset int) (int, int) {
+	before := source[:offset]
+	lines := strings.Count(before, "\n")
+	column := offset - strings.LastIndex(before, "\n")
+
+	switch {
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return node.Line + 1 + lines, column
+	case lines > 0:
+		return node.Line + lines, column
+	case node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+		return node.Line, node.Column + offset + 1
+	default:
+		return node.Line, node.Column + offset
+	}
+}
+
+func (e *expression) matches(state prState) bool {
+	return e.root.eval(state).(bool)
+}
+
+// uses reports whether the expression reads the variable.
+func (e *expression) uses(name string) bool {
+	return e != nil && e.root.uses(name)
+}
+
+func (n *exprNode) uses(name string) bool {
+	if n == nil {
+		return false
+	}
+	if n.op == "var" && n.value == name {
+		return true
+	}
+	return n.left.uses(name) || n.right.uses(name)
+}
+
+// check resolves the types of the node and its operands.
+func (n *exprNode) check() error {
+	for _, operand := range []*exprNode{n.left, n.right} {
+		if operand == nil {
+			continue
+		}
+		if err := operand.check(); err != nil {
+			return err
+		}
+	}
+
+	mismatch := func() error {
+		if n.right == nil {
+			op := n.op
+			if op == "neg" {
+				op = "-"
+			}
+			return &exprError{n.offset, fmt.Sprintf("operator %q can't be applied to %v", op, n.left.typ)}
+		}
+		return &exprError{n.offset, fmt.Sprintf("operator %q can't be applied to %v and %v", n.op, n.left.typ, n.right.typ)}
+	}
+
+	switch n.op {
+	case "lit":
+	case "var":
+		variable, ok := exprVariables[n.value.(string)]
+		if !ok {
+			return &exprError{n.offset, fmt.Sprintf("unknown variable %q", n.value)}
+		}
+		n.typ = variable.typ
+	case "!":
+		if n.left.typ != exprBool {
+			return mismatch()
+		}
+		n.typ = exprBool
+	case "neg":
+		if n.left.typ != exprInt {
+			return mismatch()
+		}
+		n.typ = exprInt
+	case "&&", "||":
+		if n.left.typ != exprBool || n.right.typ != exprBool {
+			return mismatch()
+		}
+		n.typ = exprBool
+	case "+", "-":
+		if n.left.typ != exprInt || n.right.typ != exprInt {
+			return mismatch()
+		}
+		n.typ = exprInt
+	case "<", "<=", ">", ">=":
+		if n.left.typ != exprInt || n.right.typ != exprInt {
+			return mismatch()
+		}
+		n.typ = exprBool
+	case "==", "!=":
+		if n.left.typ != n.right.typ || n.left.typ == exprList {
+			return mismatch()
+		}
+		n.typ = exprBool
+	case "in":
+		if n.left.typ != exprString || (n.right.typ != exprList && n.right.typ != exprString) {
+			return mismatch()
+		}
+		n.typ = exprBool
+	case "=~":
+		if n.left.typ != exprString || n.right.op != "lit" || n.right.typ != exprString {
+			return &exprError{n.offset, `operator "=~" needs a string and a string literal regexp`}
+		}
+		re, err := regexp.Compile(n.right.value.(string))
+		if err != nil {
+			return &exprError{n.right.offset, fmt.Sprintf("invalid regexp: %v", err)}
+		}
+		n.regexp = re
+		n.typ = exprBool
+	}
+	return nil
+}
+
+// eval evaluates a type checked node.
+func (n *exprNode) eval(state prState) interface{} {
+	switch n.op {
+	case "lit":
+		return n.value
+	case "var":
+		return exprVariables[n.value.(string)].value(state)
+	case "!":
+		return !n.left.eval(state).(bool)
+	case "neg":
+		return -n.left.eval(state).(int)
+	case "&&":
+		return n.left.eval(state).(bool) && n.right.eval(state).(bool)
+	case "||":
+		return n.left.eval(state).(bool) || n.right.eval(state).(bool)
+	case "=~":
+		return n.regexp.MatchString(n.left.eval(state).(string))
+	}
+
+	left, right := n.left.eval(state), n.right.eval(state)
+	switch n.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case "in":
+		if list, ok := right.([]string); ok {
+			return contains(list, left.(string))
+		}
+		return strings.Contains(right.(string), left.(string))
+	case "+":
+		return left.(int) + right.(int)
+	case "-":
+		return left.(int) - right.(int)
+	case "<":
+		return left.(int) < right.(int)
+	case "<=":
+		return left.(int) <= right.(int)
+	case ">":
+		return left.(int) > right.(int)
+	default:
+		return left.(int) >= right.(int)
+	}
+}
+
+type exprTokenKind int
+
+const (
+	exprEOF exprTokenKind = iota
+	exprIdent
+	exprNumber
+	exprText
+	exprOperator
+)
+
+type exprToken struct {
+	kind   exprTokenKind
+	text   string
+	offset int
+}
+
+// exprOperators are listed so that two character operators are tried first.
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "+", "-", "(", ")"}
+
+func lexExpression(source string) ([]exprToken, error) {
+	var tokens []exprToken
+	i := 0
+	for i < len(source) {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			start := i
+			for i < len(source) && isExprIdentByte(source[i]) {
+				i++
+			}
+			tokens = append(tokens, exprToken{exprIdent, source[start:i], start})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(source) && source[i] >= '0' && source[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, exprToken{exprNumber, source[start:i], start})
+		case c == '"':
+			start := i
+			for i++; i < len(source) && source[i] != '"'; i++ {
+				if source[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(source) {
+				return nil, &exprError{start, "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, exprToken{exprText, source[start:i], start})
+		default:
+			op := ""
+			for _, candidate := range exprOperators {
+				if strings.HasPrefix(source[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &exprError{i, fmt.Sprintf("unexpected character %q", c)}
+			}
+			tokens = append(tokens, exprToken{exprOperator, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, exprToken{exprEOF, "end of expression", len(source)}), nil
+}
+
+func isExprIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// exprParser is a recursive descent parser. From lowest to highest, the
+// precedence is "||", "&&", comparisons, "+" and "-", then unary operators.
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != exprEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token when it's one of the operators.
+func (p *exprParser) accept(ops ...string) (exprToken, bool) {
+	tok := p.peek()
+	if tok.kind != exprOperator && tok.kind != exprIdent {
+		return tok, false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return p.next(), true
+		}
+	}
+	return tok, false
+}
+
+func (p *exprParser) parseOr() (*exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (*exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseBinary(operand func() (*exprNode, error), ops ...string) (*exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprNode{op: tok.text, offset: tok.offset, left: left, right: right}
+	}
+}
+
+// parseComparison parses at most one comparison, so "a < b < c" is an error.
+func (p *exprParser) parseComparison() (*exprNode, error) {
+	left, err := p.parseBinary(p.parseUnary, "+", "-")
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := p.accept("==", "!=", "<", "<=", ">", ">=", "=~", "in")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseBinary(p.parseUnary, "+", "-")
+	if err != nil {
+		return nil, err
+	}
+	return &exprNode{op: tok.text, offset: tok.offset, left: left, right: right}, nil
+}
+
+func (p *exprParser) parseUnary() (*exprNode, error) {
+	if tok, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		op := tok.text
+		if op == "-" {
+			op = "neg"
+		}
+		return &exprNode{op: op, offset: tok.offset, left: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (*exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case exprNumber:
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, &exprError{tok.offset, fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		return &exprNode{op: "lit", offset: tok.offset, value: n, typ: exprInt}, nil
+	case exprText:
+		s, err := strconv.Unquote(tok.text)
+		if err != nil {
+			return nil, &exprError{tok.offset, fmt.Sprintf("invalid string %s", tok.text)}
+		}
+		return &exprNode{op: "lit", offset: tok.offset, value: s, typ: exprString}, nil
+	case exprIdent:
+		switch tok.text {
+		case "true", "false":
+			return &exprNode{op: "lit", offset: tok.offset, value: tok.text == "true", typ: exprBool}, nil
+		case "in":
+			return nil, &exprError{tok.offset, `unexpected "in"`}
+		}
+		return &exprNode{op: "var", offset: tok.offset, value: tok.text}, nil
+	case exprOperator:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, &exprError{p.peek().offset, fmt.Sprintf("expected \")\", got %q", p.peek().text)}
+			}
+			return inner, nil
+		}
+	}
+	return nil, &exprError{tok.offset, fmt.Sprintf("unexpected %q", tok.text)}
+}
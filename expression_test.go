@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpressionMatches(t *testing.T) {
+	state := prState{
+		draft:        false,
+		title:        "WIP: Bump yaml.v3",
+		author:       "dependabot[bot]",
+		authorIsBot:  true,
+		labels:       []string{"Dependencies"},
+		files:        []changedFile{{path: "go.mod"}, {path: "go.sum"}},
+		additions:    600,
+		deletions:    20,
+		approvals:    1,
+		mergeable:    true,
+		linkedIssues: nil,
+	}
+
+	tests := []struct {
+		source   string
+		expected bool
+	}{
+		{source: `additions > 500 && author != "dependabot[bot]" && !draft`, expected: false},
+		{source: `additions > 500 && !draft`, expected: true},
+		{source: `additions + deletions >= 620`, expected: true},
+		{source: `additions - deletions < 500 || author_is_bot`, expected: true},
+		{source: `!(approvals == 1)`, expected: false},
+		{source: `"Dependencies" in labels && "go.mod" in files`, expected: true},
+		{source: `"yaml" in title`, expected: true},
+		{source: `title =~ "^WIP:" && mergeable == true`, expected: true},
+		{source: `linked_issue || -approvals > -1`, expected: false},
+		{source: `true || false && false`, expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.source, func(t *testing.T) {
+			expr, err := parseExpression(tc.source)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual := expr.matches(state); actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpressionUnmarshalYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "Unknown Variable",
+			config:   "Big:\n  if: additions > 500 && auther == \"octocat\"\n",
+			expected: `line 2, column 26: invalid expression: unknown variable "auther"`,
+		},
+		{
+			name:     "Type Mismatch",
+			config:   "Big:\n  if: 'title > 5'\n",
+			expected: `line 2, column 14: invalid expression: operator ">" can't be applied to string and int`,
+		},
+		{
+			name:     "Not Bool",
+			config:   "Big:\n  if: additions + deletions\n",
+			expected: `line 2, column 17: invalid expression: expression is int, not bool`,
+		},
+		{
+			name:     "Syntax Error",
+			config:   "Big:\n  if: (draft || approved\n",
+			expected: `line 2, column 25: invalid expression: expected ")", got "end of expression"`,
+		},
+		{
+			// The indentation of block scalars isn't known, so the column is
+			// relative to the expression.
+			name:     "Invalid Regexp",
+			config:   "Big:\n  if: |\n    !draft &&\n    title =~ \"(\"\n",
+			expected: `line 4, column 10: invalid expression: invalid regexp`,
+		},
+		{
+			name:     "Chained Comparison",
+			config:   "Big:\n  if: 1 < additions < 5\n",
+			expected: `line 2, column 21: invalid expression: unexpected "<"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var config labelerConfig
+			err := yaml.Unmarshal([]byte(tc.config), &config)
+			if err == nil {
+				t.Fatalf("expected error, got: nil")
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected error containing: %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestLabelsForPRStateIf(t *testing.T) {
+	var config labelerConfig
+	err := yaml.Unmarshal([]byte(`
+Needs Split:
+  if: additions > 500 && author != "dependabot[bot]" && !draft
+Conflicts:
+  if: "!mergeable"
+`), &config)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !config.usesMergeable() || config.usesBehindBase() {
+		t.Errorf("expected expression variables to be detected")
+	}
+
+	actual, err := config.labelsForPRState(prState{additions: 501, author: "octocat", mergeable: true})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	assertStringSlicesEqual(t, []string{"Needs Split"}, actual)
+}
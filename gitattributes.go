@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// gitAttributes are the rules of a .gitattributes file, in file order.
+type gitAttributes []gitAttributesRule
+
+type gitAttributesRule struct {
+	pattern string
+	// attributes maps each attribute to its value. Set attributes are "true",
+	// unset attributes are "false" and unspecified attributes hold "".
+	attributes map[string]string
+}
+
+// parseGitAttributes reads the pattern lines of a .gitattributes file.
+// Comments, macro definitions and quoted patterns are skipped.
+func parseGitAttributes(data []byte) gitAttributes {
+	var rules gitAttributes
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 ||
+			strings.HasPrefix(fields[0], "#") ||
+			strings.HasPrefix(fields[0], "[attr]") ||
+			strings.HasPrefix(fields[0], `"`) {
+			continue
+		}
+
+		rule := gitAttributesRule{
+			pattern:    fields[0],
+			attributes: make(map[string]string, len(fields)-1),
+		}
+		for _, attr := range fields[1:] {
+			switch {
+			case strings.HasPrefix(attr, "-"):
+				rule.attributes[attr[1:]] = "false"
+			case strings.HasPrefix(attr, "!"):
+				rule.attributes[attr[1:]] = ""
+			case strings.Contains(attr, "="):
+				split := strings.SplitN(attr, "=", 2)
+				rule.attributes[split[0]] = split[1]
+			default:
+				rule.attributes[attr] = "true"
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// value returns the value of attribute for the path, where later rules
+// take precedence over earlier ones.
+func (a gitAttributes) value(path, attribute string) string {
+	for i := len(a) - 1; i >= 0; i-- {
+		value, ok := a[i].attributes[attribute]
+		if !ok {
+			continue
+		}
+		// Invalid patterns never match, the same as git.
+		if matched, _ := matchGitGlob(a[i].pattern, path); matched {
+			return value
+		}
+	}
+	return ""
+}
+
+// generated reports whether linguist treats the path as generated or vendored.
+func (a gitAttributes) generated(path string) bool {
+	return a.value(path, "linguist-generated") == "true" ||
+		a.value(path, "linguist-vendored") == "true"
+}
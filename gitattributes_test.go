@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestGitAttributesGenerated(t *testing.T) {
+	attributes := parseGitAttributes([]byte(`
+# Generated code.
+*.pb.go linguist-generated
+api/**/*.json linguist-generated=true
+vendor/** linguist-vendored
+vendor/internal/** -linguist-vendored
+[attr]binary -diff -merge -text
+docs/vendor/** linguist-vendored linguist-documentation
+docs/vendor/keep.md !linguist-vendored
+`))
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "widgets.pb.go", expected: true},
+		{path: "proto/widgets.pb.go", expected: true},
+		{path: "api/v1/widgets.json", expected: true},
+		{path: "widgets.json", expected: false},
+		{path: "vendor/gopkg.in/yaml.v3/yaml.go", expected: true},
+		{path: "vendor/internal/patch.go", expected: false},
+		{path: "docs/vendor/lib.md", expected: true},
+		{path: "docs/vendor/keep.md", expected: false},
+		{path: "main.go", expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			actual := attributes.generated(tc.path)
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v29/github"
 	"golang.org/x/oauth2"
@@ -14,6 +17,15 @@ var (
 	// ErrInvalidRepository occurs when an invalid repo string is passed
 	// into NewRepositoryClient.
 	ErrInvalidRepository = errors.New("invalid repository format")
+
+	// ErrFileNotFound occurs when a requested file doesn't exist in the repository.
+	ErrFileNotFound = errors.New("file not found")
+
+	// ErrIssueNotFound occurs when a requested issue doesn't exist or is a pull request.
+	ErrIssueNotFound = errors.New("issue not found")
+
+	// ErrMissingPermission occurs when the token can't read the requested resource.
+	ErrMissingPermission = errors.New("missing permission")
 )
 
 // RepositoryClient is a Github client with operations targetted
@@ -22,6 +34,20 @@ type RepositoryClient struct {
 	client *github.Client
 	owner  string
 	name   string
+
+	// httpClient authenticates the requests to graphQLURL.
+	httpClient *http.Client
+	graphQLURL string
+
+	// mergeableRetries and mergeableRetryDelay bound the wait for Github to
+	// compute whether a pull request is mergeable.
+	mergeableRetries    int
+	mergeableRetryDelay time.Duration
+
+	// Lookups cached for the lifetime of the client.
+	visibleTeams map[string]bool
+	teamMembers  map[string]bool
+	permissions  map[string]string
 }
 
 // NewRepositoryClient initializes a oauth client and formats data for
@@ -42,6 +68,16 @@ func NewRepositoryClient(token, repo string) (*RepositoryClient, error) {
 		client: client,
 		owner:  owner,
 		name:   name,
+
+		httpClient: oauthClient,
+		graphQLURL: defaultGraphQLURL,
+
+		mergeableRetries:    5,
+		mergeableRetryDelay: 2 * time.Second,
+
+		visibleTeams: make(map[string]bool),
+		teamMembers:  make(map[string]bool),
+		permissions:  make(map[string]string),
 	}, nil
 }
 
@@ -55,6 +91,9 @@ func (r RepositoryClient) DownloadFileFromDefaultBranch(path string) ([]byte, er
 		path,
 		nil, // Optional SHA, defaults to repos default branch.
 	)
+	if isNotFound(err) {
+		return nil, fmt.Errorf("%w: %q", ErrFileNotFound, path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("github client error: %w", err)
 	}
@@ -71,6 +110,22 @@ func (r RepositoryClient) DownloadFileFromDefaultBranch(path string) ([]byte, er
 	return []byte(fileContent), nil
 }
 
+// isForbidden reports whether err is a 403 response from the Github API.
+func isForbidden(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) &&
+		errResp.Response != nil &&
+		errResp.Response.StatusCode == http.StatusForbidden
+}
+
+// isNotFound reports whether err is a 404 response from the Github API.
+func isNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) &&
+		errResp.Response != nil &&
+		errResp.Response.StatusCode == http.StatusNotFound
+}
+
 // ReplaceLabelsForIssue replaces the set of labels on an issue within the repository.
 func (r RepositoryClient) ReplaceLabelsForIssue(number int, labels []string) error {
 	_, _, err := r.client.Issues.ReplaceLabelsForIssue(context.TODO(), r.owner, r.name, number, labels)
@@ -80,10 +135,328 @@ func (r RepositoryClient) ReplaceLabelsForIssue(number int, labels []string) err
 	return nil
 }
 
-// Review is the current state of a pull request review.
-type Review int
+// EditLabelsForIssue adds and removes labels on an issue, leaving its other
+// labels untouched. The repository is in the "owner/name" format, or empty
+// for the client's repository.
+func (r RepositoryClient) EditLabelsForIssue(repo string, number int, add, remove []string) error {
+	owner, name, err := r.repository(repo)
+	if err != nil {
+		return err
+	}
 
-func (r Review) String() string {
+	if len(add) > 0 {
+		_, _, err := r.client.Issues.AddLabelsToIssue(context.TODO(), owner, name, number, add)
+		if err != nil {
+			return fmt.Errorf("failed to add labels: %w", err)
+		}
+	}
+	for _, label := range remove {
+		// go-github doesn't escape the label, which may contain slashes or spaces.
+		_, err := r.client.Issues.RemoveLabelForIssue(context.TODO(), owner, name, number, url.PathEscape(label))
+		// The label was already removed.
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove label %q: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// PullRequestFile is a file changed by a pull request.
+type PullRequestFile struct {
+	Filename  string
+	Status    string
+	Additions int
+	Deletions int
+}
+
+// ListFiles returns every file changed by the pull request.
+func (r RepositoryClient) ListFiles(number int) ([]PullRequestFile, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var files []PullRequestFile
+	for {
+		page, resp, err := r.client.PullRequests.ListFiles(context.TODO(), r.owner, r.name, number, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files for pull request: %w", err)
+		}
+		for _, file := range page {
+			files = append(files, PullRequestFile{
+				Filename:  file.GetFilename(),
+				Status:    file.GetStatus(),
+				Additions: file.GetAdditions(),
+				Deletions: file.GetDeletions(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+// IsTeamMember reports whether the user is an active member of the team. The
+// team is a slug within the repository owner's organization, or "org/slug"
+// for a team of another organization.
+func (r RepositoryClient) IsTeamMember(team, user string) (bool, error) {
+	org, slug := r.owner, strings.TrimPrefix(team, "@")
+	if split := strings.SplitN(slug, "/", 2); len(split) == 2 {
+		org, slug = split[0], split[1]
+	}
+	teamKey := org + "/" + slug
+	memberKey := teamKey + ":" + strings.ToLower(user)
+	if member, ok := r.teamMembers[memberKey]; ok {
+		return member, nil
+	}
+
+	// Membership lookups respond 404 for both non-members and teams the token
+	// can't see, so confirm the team is visible first.
+	if !r.visibleTeams[teamKey] {
+		_, _, err := r.client.Teams.GetTeamBySlug(context.TODO(), org, slug)
+		if isNotFound(err) || isForbidden(err) {
+			return false, fmt.Errorf(
+				"%w: team %q is not visible, reading team membership needs a token with the read:org scope",
+				ErrMissingPermission, teamKey,
+			)
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to get team %q: %w", teamKey, err)
+		}
+		if r.visibleTeams != nil {
+			r.visibleTeams[teamKey] = true
+		}
+	}
+
+	u := fmt.Sprintf("orgs/%v/teams/%v/memberships/%v", org, slug, user)
+	req, err := r.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create team membership request: %w", err)
+	}
+	membership := new(github.Membership)
+	_, err = r.client.Do(context.TODO(), req, membership)
+	if isForbidden(err) {
+		return false, fmt.Errorf(
+			"%w: reading membership of team %q needs a token with the read:org scope",
+			ErrMissingPermission, teamKey,
+		)
+	}
+	if err != nil && !isNotFound(err) {
+		return false, fmt.Errorf("failed to get membership of team %q: %w", teamKey, err)
+	}
+
+	member := err == nil && membership.GetState() == "active"
+	if r.teamMembers != nil {
+		r.teamMembers[memberKey] = member
+	}
+	return member, nil
+}
+
+// PullRequest returns the pull request with the number.
+func (r RepositoryClient) PullRequest(number int) (*github.PullRequest, error) {
+	pr, _, err := r.client.PullRequests.Get(context.TODO(), r.owner, r.name, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	return pr, nil
+}
+
+// Mergeable reports whether the pull request can be merged without conflicts.
+// Github computes mergeability in the background and reports it as null until
+// it's known, so the pull request is fetched again a bounded number of times.
+// The result is nil when mergeability is still unknown.
+func (r RepositoryClient) Mergeable(number int) (*bool, error) {
+	for attempt := 0; ; attempt++ {
+		pr, err := r.PullRequest(number)
+		if err != nil {
+			return nil, err
+		}
+		if pr.Mergeable != nil || attempt >= r.mergeableRetries {
+			return pr.Mergeable, nil
+		}
+		time.Sleep(r.mergeableRetryDelay)
+	}
+}
+
+// BehindBy returns the number of commits on base that head doesn't include.
+func (r RepositoryClient) BehindBy(base, head string) (int, error) {
+	comparison, _, err := r.client.Repositories.CompareCommits(context.TODO(), r.owner, r.name, base, head)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compare %q with %q: %w", head, base, err)
+	}
+	return comparison.GetBehindBy(), nil
+}
+
+// repository splits an "owner/name" repository, defaulting to the client's
+// repository when it's empty.
+func (r RepositoryClient) repository(repo string) (string, string, error) {
+	if repo == "" {
+		return r.owner, r.name, nil
+	}
+	split := strings.Split(repo, "/")
+	if len(split) != 2 {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidRepository, repo)
+	}
+	return split[0], split[1], nil
+}
+
+// Issue is an issue of a repository.
+type Issue struct {
+	Number int
+	State  string
+	Labels []string
+}
+
+// Issue returns the issue with the number. The repository is in the
+// "owner/name" format, or empty for the client's repository.
+func (r RepositoryClient) Issue(repo string, number int) (Issue, error) {
+	owner, name, err := r.repository(repo)
+	if err != nil {
+		return Issue{}, err
+	}
+
+	issue, _, err := r.client.Issues.Get(context.TODO(), owner, name, number)
+	// Pull requests are issues too, but they don't link other pull requests.
+	if isNotFound(err) || (err == nil && issue.IsPullRequest()) {
+		return Issue{}, fmt.Errorf("%w: %v/%v#%d", ErrIssueNotFound, owner, name, number)
+	}
+	if err != nil {
+		return Issue{}, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	labels := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	return Issue{
+		Number: issue.GetNumber(),
+		State:  issue.GetState(),
+		Labels: labels,
+	}, nil
+}
+
+// PullRequestsForHead returns the numbers of the open pull requests whose
+// head is the commit.
+func (r RepositoryClient) PullRequestsForHead(sha string) ([]int, error) {
+	opt := &github.PullRequestListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var numbers []int
+	for {
+		prs, resp, err := r.client.PullRequests.ListPullRequestsWithCommit(context.TODO(), r.owner, r.name, sha, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for commit: %w", err)
+		}
+		for _, pr := range prs {
+			if pr.GetState() == "open" && pr.GetHead().GetSHA() == sha {
+				numbers = append(numbers, pr.GetNumber())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return numbers, nil
+}
+
+// CheckResult is the outcome of a check run or commit status context. The
+// conclusion is "pending" until the check completes.
+type CheckResult struct {
+	Name       string
+	Conclusion string
+}
+
+// CombinedStatus returns the latest commit status of each context on the ref.
+func (r RepositoryClient) CombinedStatus(ref string) ([]CheckResult, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var results []CheckResult
+	for {
+		combined, resp, err := r.client.Repositories.GetCombinedStatus(context.TODO(), r.owner, r.name, ref, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get combined status: %w", err)
+		}
+		for _, status := range combined.Statuses {
+			results = append(results, CheckResult{
+				Name:       status.GetContext(),
+				Conclusion: status.GetState(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return results, nil
+}
+
+// ListCheckRuns returns the latest check run of each name on the ref.
+func (r RepositoryClient) ListCheckRuns(ref string) ([]CheckResult, error) {
+	opt := &github.ListCheckRunsOptions{
+		Filter:      github.String("latest"),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var results []CheckResult
+	for {
+		runs, resp, err := r.client.Checks.ListCheckRunsForRef(context.TODO(), r.owner, r.name, ref, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list check runs: %w", err)
+		}
+		for _, run := range runs.CheckRuns {
+			conclusion := "pending"
+			if run.GetStatus() == "completed" {
+				conclusion = run.GetConclusion()
+			}
+			results = append(results, CheckResult{
+				Name:       run.GetName(),
+				Conclusion: conclusion,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return results, nil
+}
+
+// PermissionLevel returns the user's permission on the repository, one of
+// "admin", "write", "read" or "none". Users that aren't collaborators, such
+// as most bot accounts, have no permission.
+func (r RepositoryClient) PermissionLevel(user string) (string, error) {
+	key := strings.ToLower(user)
+	if permission, ok := r.permissions[key]; ok {
+		return permission, nil
+	}
+
+	level, _, err := r.client.Repositories.GetPermissionLevel(context.TODO(), r.owner, r.name, user)
+	if isNotFound(err) {
+		level, err = &github.RepositoryPermissionLevel{Permission: github.String("none")}, nil
+	}
+	if isForbidden(err) {
+		return "", fmt.Errorf(
+			"%w: reading the permission of %q needs a token with push access to the repository",
+			ErrMissingPermission, user,
+		)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get permission level: %w", err)
+	}
+
+	permission := level.GetPermission()
+	if r.permissions != nil {
+		r.permissions[key] = permission
+	}
+	return permission, nil
+}
+
+// ReviewState is the state of a pull request review.
+type ReviewState int
+
+func (r ReviewState) String() string {
 	switch r {
 	case Approved:
 		return "approved"
@@ -93,29 +466,52 @@ func (r Review) String() string {
 		return "dismissed"
 	case Commented:
 		return "commented"
+	case Pending:
+		return "pending"
 	default:
 		return "UNKNOWN"
 	}
 }
 
-// Review enum declaration.
+// ReviewState enum declaration.
 const (
-	Unknown Review = iota
+	Unknown ReviewState = iota
 	Approved
 	ChangesRequested
 	Dismissed
 	Commented
+	Pending
 )
 
-// Map lookup table from string to Review enum.
-var reviewLookupTable = map[string]Review{
+// Map lookup table from string to ReviewState enum.
+var reviewLookupTable = map[string]ReviewState{
 	"approved":          Approved,
 	"changes_requested": ChangesRequested,
 	"dismissed":         Dismissed,
 	"commented":         Commented,
+	"pending":           Pending,
+}
+
+// Review is a reviewer's current review of a pull request.
+type Review struct {
+	User  string
+	IsBot bool
+	State ReviewState
+	// CommitID is the head of the pull request when the review was submitted.
+	CommitID    string
+	SubmittedAt time.Time
+}
+
+func (r Review) String() string {
+	return r.User + ":" + r.State.String()
+}
+
+// IsBot reports whether the user is a Github App or other bot account.
+func IsBot(user *github.User) bool {
+	return user.GetType() == "Bot" || strings.HasSuffix(user.GetLogin(), "[bot]")
 }
 
-// PullRequestReviews returns a slice of review states on the pull request.
+// PullRequestReviews returns the current review of each reviewer on the pull request.
 func (r RepositoryClient) PullRequestReviews(number int) ([]Review, error) {
 	opt := &github.ListOptions{PerPage: 100}
 	var allReviews []*github.PullRequestReview
@@ -134,22 +530,123 @@ func (r RepositoryClient) PullRequestReviews(number int) ([]Review, error) {
 	return normalizedReviews(allReviews), nil
 }
 
-// normalizedReviews takes a slices of reviews and returns a list of each users latest review state.
+// normalizedReviews takes a slices of reviews and returns each users current review.
+//
+// Dismissing a review changes its state in place, so a dismissed review
+// leaves the user with their previous approval or change request. Users
+// with only dismissed reviews are reported as dismissed.
 func normalizedReviews(reviews []*github.PullRequestReview) []Review {
 	// Reviews are in chronological order, overwrite previous reviews of the same user.
-	statePerUser := make(map[int64]Review)
+	reviewPerUser := make(map[int64]Review)
 	for _, review := range reviews {
 		state := reviewLookupTable[strings.ToLower(review.GetState())]
+		id := review.GetUser().GetID()
+
+		switch state {
+		case Approved, ChangesRequested:
+		case Dismissed:
+			if _, ok := reviewPerUser[id]; ok {
+				continue
+			}
+		default:
+			// Comments aren't actual reviews, and pending reviews haven't been submitted.
+			continue
+		}
 
-		// Ignore the commented state, as they aren't actual reviews.
-		if state != Commented {
-			statePerUser[review.GetUser().GetID()] = state
+		reviewPerUser[id] = Review{
+			User:        review.GetUser().GetLogin(),
+			IsBot:       IsBot(review.GetUser()),
+			State:       state,
+			CommitID:    review.GetCommitID(),
+			SubmittedAt: review.GetSubmittedAt(),
 		}
 	}
 
-	states := make([]Review, 0, len(statePerUser))
-	for _, review := range statePerUser {
+	states := make([]Review, 0, len(reviewPerUser))
+	for _, review := range reviewPerUser {
 		states = append(states, review)
 	}
 	return states
 }
+
+// TimelineEvent is an event in the timeline of a pull request, such as a
+// review, comment, commit or review request.
+type TimelineEvent struct {
+	Event string
+	// Actor is the login of the user behind the event. Commits have no actor.
+	Actor string
+	IsBot bool
+	// ReviewState is the state of a "reviewed" event.
+	ReviewState ReviewState
+	CreatedAt   time.Time
+}
+
+// timelineEvent is the raw timeline event. go-github doesn't decode the
+// reviewer, review state or commit dates of timeline events.
+type timelineEvent struct {
+	Event       string       `json:"event"`
+	Actor       *github.User `json:"actor"`
+	User        *github.User `json:"user"`
+	State       string       `json:"state"`
+	CreatedAt   *time.Time   `json:"created_at"`
+	SubmittedAt *time.Time   `json:"submitted_at"`
+	Committer   *struct {
+		Date time.Time `json:"date"`
+	} `json:"committer"`
+}
+
+// PullRequestTimeline returns the events of the pull request in chronological order.
+func (r RepositoryClient) PullRequestTimeline(number int) ([]TimelineEvent, error) {
+	var events []TimelineEvent
+	page := 1
+	for {
+		u := fmt.Sprintf("repos/%v/%v/issues/%v/timeline?per_page=100&page=%d", r.owner, r.name, number, page)
+		req, err := r.client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create timeline request: %w", err)
+		}
+		req.Header.Set("Accept", "application/vnd.github.mockingbird-preview")
+
+		var raw []timelineEvent
+		resp, err := r.client.Do(context.TODO(), req, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list timeline for pull request: %w", err)
+		}
+		for _, e := range raw {
+			events = append(events, e.normalized())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	return events, nil
+}
+
+func (e timelineEvent) normalized() TimelineEvent {
+	event := TimelineEvent{Event: e.Event}
+
+	user := e.Actor
+	if e.User != nil {
+		user = e.User
+	}
+	if user != nil {
+		event.Actor = user.GetLogin()
+		event.IsBot = IsBot(user)
+	}
+
+	switch {
+	case e.SubmittedAt != nil:
+		event.CreatedAt = *e.SubmittedAt
+	case e.CreatedAt != nil:
+		event.CreatedAt = *e.CreatedAt
+	case e.Committer != nil:
+		event.CreatedAt = e.Committer.Date
+	}
+
+	if e.Event == "reviewed" {
+		event.ReviewState = reviewLookupTable[strings.ToLower(e.State)]
+	}
+	return event
+}
@@ -2,7 +2,13 @@ package github
 
 import (
 	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v29/github"
 )
@@ -65,16 +71,519 @@ func TestNewRepositoryClient(t *testing.T) {
 	}
 }
 
+// newTestClient returns a client for the "owner/name" repository that sends
+// its requests to the server.
+func newTestClient(t *testing.T, server *httptest.Server) *RepositoryClient {
+	client, err := NewRepositoryClient("fake-token", "owner/name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.graphQLURL = server.URL + "/graphql"
+	return client
+}
+
+func TestListFiles(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/pulls/7/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("expected per_page: 100, got: %q", r.URL.Query().Get("per_page"))
+		}
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[{"filename": "docs/README.md", "status": "added", "additions": 12}]`))
+			return
+		}
+		w.Header().Set("Link", `<`+serverURL(r)+`/repos/owner/name/pulls/7/files?page=2>; rel="next"`)
+		w.Write([]byte(`[
+			{"filename": "main.go", "status": "modified", "additions": 3, "deletions": 1},
+			{"filename": "old.go", "status": "removed", "deletions": 40}
+		]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	files, err := client.ListFiles(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []PullRequestFile{
+		{Filename: "main.go", Status: "modified", Additions: 3, Deletions: 1},
+		{Filename: "old.go", Status: "removed", Deletions: 40},
+		{Filename: "docs/README.md", Status: "added", Additions: 12},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got: %v", len(expected), files)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("expected file %d: %+v, got: %+v", i, expected[i], files[i])
+		}
+	}
+}
+
+func TestIsTeamMember(t *testing.T) {
+	requests := make(map[string]int)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/owner/teams/platform", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Write([]byte(`{"slug": "platform"}`))
+	})
+	mux.HandleFunc("/orgs/owner/teams/platform/memberships/octocat", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Write([]byte(`{"state": "active"}`))
+	})
+	mux.HandleFunc("/orgs/owner/teams/platform/memberships/hubot", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/orgs/other/teams/secret", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	tests := []struct {
+		name        string
+		team        string
+		user        string
+		expected    bool
+		expectedErr error
+	}{
+		{name: "Member", team: "platform", user: "octocat", expected: true},
+		{name: "Cached Member", team: "@owner/platform", user: "OctoCat", expected: true},
+		{name: "Not Member", team: "platform", user: "hubot", expected: false},
+		{name: "Invisible Team", team: "other/secret", user: "octocat", expectedErr: ErrMissingPermission},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := client.IsTeamMember(tc.team, tc.user)
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+
+	if requests["/orgs/owner/teams/platform"] != 1 {
+		t.Errorf("expected team to be fetched once, got: %d", requests["/orgs/owner/teams/platform"])
+	}
+	if requests["/orgs/owner/teams/platform/memberships/octocat"] != 1 {
+		t.Errorf("expected membership to be fetched once, got: %d", requests["/orgs/owner/teams/platform/memberships/octocat"])
+	}
+}
+
+func TestPullRequestTimeline(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/issues/7/timeline", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[
+				{"event": "commented", "actor": {"login": "codecov[bot]", "type": "Bot"}, "created_at": "2020-01-03T00:00:00Z"},
+				{"event": "review_requested", "actor": {"login": "author"}, "created_at": "2020-01-04T00:00:00Z"}
+			]`))
+			return
+		}
+		w.Header().Set("Link", `<`+serverURL(r)+`/repos/owner/name/issues/7/timeline?page=2>; rel="next"`)
+		w.Write([]byte(`[
+			{"event": "committed", "committer": {"date": "2020-01-01T00:00:00Z"}},
+			{"event": "reviewed", "user": {"login": "octocat"}, "state": "changes_requested", "submitted_at": "2020-01-02T00:00:00Z"}
+		]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	events, err := client.PullRequestTimeline(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []TimelineEvent{
+		{Event: "committed", CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Event: "reviewed", Actor: "octocat", ReviewState: ChangesRequested, CreatedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Event: "commented", Actor: "codecov[bot]", IsBot: true, CreatedAt: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{Event: "review_requested", Actor: "author", CreatedAt: time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got: %v", len(expected), events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("expected event %d: %+v, got: %+v", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestPullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 7, "title": "Bump yaml.v3", "additions": 12, "head": {"sha": "abc123"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	pr, err := client.PullRequest(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr.GetNumber() != 7 || pr.GetTitle() != "Bump yaml.v3" || pr.GetAdditions() != 12 || pr.GetHead().GetSHA() != "abc123" {
+		t.Errorf("unexpected pull request: %v", pr)
+	}
+
+	if _, err := client.PullRequest(8); err == nil {
+		t.Errorf("expected error for missing pull request, got: nil")
+	}
+}
+
+func TestMergeable(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []string
+		expected  *bool
+		requests  int
+	}{
+		{
+			name:      "Known",
+			responses: []string{`{"number": 7, "mergeable": false}`},
+			expected:  boolToPtr(false),
+			requests:  1,
+		},
+		{
+			name:      "Computed After Retry",
+			responses: []string{`{"number": 7, "mergeable": null}`, `{"number": 7}`, `{"number": 7, "mergeable": true}`},
+			expected:  boolToPtr(true),
+			requests:  3,
+		},
+		{
+			name:      "Never Computed",
+			responses: []string{`{"number": 7}`},
+			expected:  nil,
+			requests:  3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := tc.responses[len(tc.responses)-1]
+				if requests < len(tc.responses) {
+					response = tc.responses[requests]
+				}
+				requests++
+				w.Write([]byte(response))
+			}))
+			defer server.Close()
+			client := newTestClient(t, server)
+			client.mergeableRetries = 2
+			client.mergeableRetryDelay = 0
+
+			actual, err := client.Mergeable(7)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (actual == nil) != (tc.expected == nil) || (actual != nil && *actual != *tc.expected) {
+				t.Errorf("expected: %v, got: %v", tc.expected, actual)
+			}
+			if requests != tc.requests {
+				t.Errorf("expected %d requests, got: %d", tc.requests, requests)
+			}
+		})
+	}
+}
+
+func TestBehindBy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/compare/master...abc123", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "diverged", "ahead_by": 2, "behind_by": 3}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	behind, err := client.BehindBy("master", "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if behind != 3 {
+		t.Errorf("expected: 3, got: %d", behind)
+	}
+}
+
+func TestIssue(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/issues/12", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 12, "state": "open", "labels": [{"name": "priority/high"}, {"name": "area/api"}]}`))
+	})
+	mux.HandleFunc("/repos/other/repo/issues/45", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 45, "state": "closed", "labels": []}`))
+	})
+	mux.HandleFunc("/repos/owner/name/issues/13", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 13, "state": "open", "pull_request": {"url": "https://api.github.com/repos/owner/name/pulls/13"}}`))
+	})
+	mux.HandleFunc("/repos/owner/name/issues/14", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	tests := []struct {
+		name        string
+		repo        string
+		number      int
+		expected    Issue
+		expectedErr error
+	}{
+		{
+			name:     "Same Repository",
+			number:   12,
+			expected: Issue{Number: 12, State: "open", Labels: []string{"priority/high", "area/api"}},
+		},
+		{
+			name:     "Other Repository",
+			repo:     "other/repo",
+			number:   45,
+			expected: Issue{Number: 45, State: "closed", Labels: []string{}},
+		},
+		{name: "Pull Request", number: 13, expectedErr: ErrIssueNotFound},
+		{name: "Missing", number: 14, expectedErr: ErrIssueNotFound},
+		{name: "Invalid Repository", repo: "other", number: 1, expectedErr: ErrInvalidRepository},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := client.Issue(tc.repo, tc.number)
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.Number != tc.expected.Number || actual.State != tc.expected.State ||
+				strings.Join(actual.Labels, ",") != strings.Join(tc.expected.Labels, ",") {
+				t.Errorf("expected: %+v, got: %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPullRequestsForHead(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/commits/abc123/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[{"number": 9, "state": "open", "head": {"sha": "abc123"}}]`))
+			return
+		}
+		w.Header().Set("Link", `<`+serverURL(r)+`/repos/owner/name/commits/abc123/pulls?page=2>; rel="next"`)
+		w.Write([]byte(`[
+			{"number": 7, "state": "open", "head": {"sha": "abc123"}},
+			{"number": 5, "state": "closed", "head": {"sha": "abc123"}},
+			{"number": 8, "state": "open", "head": {"sha": "def456"}}
+		]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	numbers, err := client.PullRequestsForHead("abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(numbers) != 2 || numbers[0] != 7 || numbers[1] != 9 {
+		t.Errorf("expected: [7 9], got: %v", numbers)
+	}
+}
+
+func TestCombinedStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`{"state": "failure", "statuses": [{"context": "coverage", "state": "failure"}]}`))
+			return
+		}
+		w.Header().Set("Link", `<`+serverURL(r)+`/repos/owner/name/commits/abc123/status?page=2>; rel="next"`)
+		w.Write([]byte(`{"state": "failure", "statuses": [
+			{"context": "ci/build", "state": "success"},
+			{"context": "ci/deploy", "state": "pending"}
+		]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	results, err := client.CombinedStatus("abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []CheckResult{
+		{Name: "ci/build", Conclusion: "success"},
+		{Name: "ci/deploy", Conclusion: "pending"},
+		{Name: "coverage", Conclusion: "failure"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got: %v", len(expected), results)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("expected result %d: %+v, got: %+v", i, expected[i], results[i])
+		}
+	}
+}
+
+func TestListCheckRuns(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("filter") != "latest" {
+			t.Errorf("expected filter: latest, got: %q", r.URL.Query().Get("filter"))
+		}
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`{"total_count": 3, "check_runs": [{"name": "lint", "status": "completed", "conclusion": "failure"}]}`))
+			return
+		}
+		w.Header().Set("Link", `<`+serverURL(r)+`/repos/owner/name/commits/abc123/check-runs?filter=latest&page=2>; rel="next"`)
+		w.Write([]byte(`{"total_count": 3, "check_runs": [
+			{"name": "build", "status": "completed", "conclusion": "success"},
+			{"name": "test", "status": "in_progress"}
+		]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	results, err := client.ListCheckRuns("abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []CheckResult{
+		{Name: "build", Conclusion: "success"},
+		{Name: "test", Conclusion: "pending"},
+		{Name: "lint", Conclusion: "failure"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got: %v", len(expected), results)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("expected result %d: %+v, got: %+v", i, expected[i], results[i])
+		}
+	}
+}
+
+func TestPermissionLevel(t *testing.T) {
+	requests := make(map[string]int)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/name/collaborators/octocat/permission", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Write([]byte(`{"permission": "write", "user": {"login": "octocat"}}`))
+	})
+	mux.HandleFunc("/repos/owner/name/collaborators/hubot/permission", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Must have push access to view collaborator permission."}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	for _, user := range []string{"octocat", "OctoCat"} {
+		permission, err := client.PermissionLevel(user)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if permission != "write" {
+			t.Errorf("expected permission: write, got: %q", permission)
+		}
+	}
+	if requests["/repos/owner/name/collaborators/octocat/permission"] != 1 {
+		t.Errorf("expected permission to be fetched once, got: %d", requests["/repos/owner/name/collaborators/octocat/permission"])
+	}
+
+	_, err := client.PermissionLevel("hubot")
+	if !errors.Is(err, ErrMissingPermission) {
+		t.Errorf("expected ErrMissingPermission, got: %v", err)
+	}
+
+	// Users that aren't collaborators have no permission.
+	permission, err := client.PermissionLevel("renovate[bot]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if permission != "none" {
+		t.Errorf("expected permission: none, got: %q", permission)
+	}
+}
+
+func TestEditLabelsForIssue(t *testing.T) {
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/other/repo/issues/45/labels", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+strings.TrimSpace(string(body)))
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/repos/other/repo/issues/45/labels/", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		if strings.HasSuffix(r.URL.Path, "/status/approved") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	err := client.EditLabelsForIssue("other/repo", 45, []string{"status/in-review"}, []string{"status/approved", "status/draft"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		`POST ["status/in-review"]`,
+		"DELETE /repos/other/repo/issues/45/labels/status%2Fapproved",
+		"DELETE /repos/other/repo/issues/45/labels/status%2Fdraft",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected requests: %q, got: %q", expected, requests)
+	}
+}
+
+// serverURL returns the base URL of the test server handling the request.
+func serverURL(r *http.Request) string {
+	return "http://" + r.Host
+}
+
 func TestNormalizedReviews(t *testing.T) {
 	approved := Approved.String()
 	changesRequested := ChangesRequested.String()
 	dismissed := Dismissed.String()
 	commented := Commented.String()
+	pending := Pending.String()
 
 	tests := []struct {
 		name     string
 		reviews  []*github.PullRequestReview
-		expected []Review
+		expected []ReviewState
 	}{
 		{
 			name:     "Nil",
@@ -84,7 +593,7 @@ func TestNormalizedReviews(t *testing.T) {
 		{
 			name:     "Empty",
 			reviews:  []*github.PullRequestReview{},
-			expected: []Review{},
+			expected: []ReviewState{},
 		},
 		{
 			name: "One Of Each",
@@ -93,7 +602,7 @@ func TestNormalizedReviews(t *testing.T) {
 				&github.PullRequestReview{State: &changesRequested, User: &github.User{ID: int64ToPtr(2)}},
 				&github.PullRequestReview{State: &dismissed, User: &github.User{ID: int64ToPtr(3)}},
 			},
-			expected: []Review{
+			expected: []ReviewState{
 				Approved,
 				ChangesRequested,
 				Dismissed,
@@ -108,7 +617,7 @@ func TestNormalizedReviews(t *testing.T) {
 				&github.PullRequestReview{State: &approved, User: &github.User{ID: int64ToPtr(2)}},
 				&github.PullRequestReview{State: &approved, User: &github.User{ID: int64ToPtr(1)}},
 			},
-			expected: []Review{
+			expected: []ReviewState{
 				Approved,
 				Approved,
 			},
@@ -121,25 +630,84 @@ func TestNormalizedReviews(t *testing.T) {
 				&github.PullRequestReview{State: &commented, User: &github.User{ID: int64ToPtr(2)}},
 				&github.PullRequestReview{State: &commented, User: &github.User{ID: int64ToPtr(1)}},
 			},
-			expected: []Review{
+			expected: []ReviewState{
+				ChangesRequested,
+			},
+		},
+		{
+			name: "Dismissed Reverts To Previous State",
+			reviews: []*github.PullRequestReview{
+				&github.PullRequestReview{State: &changesRequested, User: &github.User{ID: int64ToPtr(1)}},
+				&github.PullRequestReview{State: &dismissed, User: &github.User{ID: int64ToPtr(1)}},
+				&github.PullRequestReview{State: &approved, User: &github.User{ID: int64ToPtr(2)}},
+				&github.PullRequestReview{State: &dismissed, User: &github.User{ID: int64ToPtr(2)}},
+			},
+			expected: []ReviewState{
 				ChangesRequested,
+				Approved,
+			},
+		},
+		{
+			name: "Ignores pending and unknown reviews",
+			reviews: []*github.PullRequestReview{
+				&github.PullRequestReview{State: &approved, User: &github.User{ID: int64ToPtr(1)}},
+				&github.PullRequestReview{State: &pending, User: &github.User{ID: int64ToPtr(1)}},
+				&github.PullRequestReview{State: stringToPtr("SOMETHING_NEW"), User: &github.User{ID: int64ToPtr(2)}},
+			},
+			expected: []ReviewState{
+				Approved,
 			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			actual := normalizedReviews(tc.reviews)
+			var actual []ReviewState
+			for _, review := range normalizedReviews(tc.reviews) {
+				actual = append(actual, review.State)
+			}
 			assertReviewSlicesEqualUnordered(t, tc.expected, actual)
 		})
 	}
 }
 
+func TestNormalizedReviewsIdentity(t *testing.T) {
+	approved := Approved.String()
+	submitted := time.Date(2020, 2, 1, 12, 0, 0, 0, time.UTC)
+	reviews := normalizedReviews([]*github.PullRequestReview{
+		{
+			State:       &approved,
+			CommitID:    stringToPtr("abc123"),
+			SubmittedAt: &submitted,
+			User:        &github.User{ID: int64ToPtr(1), Login: stringToPtr("renovate[bot]"), Type: stringToPtr("Bot")},
+		},
+	})
+
+	expected := Review{
+		User:        "renovate[bot]",
+		IsBot:       true,
+		State:       Approved,
+		CommitID:    "abc123",
+		SubmittedAt: submitted,
+	}
+	if len(reviews) != 1 || reviews[0] != expected {
+		t.Fatalf("expected: %v, got: %v", []Review{expected}, reviews)
+	}
+}
+
+func boolToPtr(b bool) *bool {
+	return &b
+}
+
 func int64ToPtr(i int64) *int64 {
 	return &i
 }
 
-func assertReviewSlicesEqualUnordered(t *testing.T, a, b []Review) {
+func stringToPtr(s string) *string {
+	return &s
+}
+
+func assertReviewSlicesEqualUnordered(t *testing.T, a, b []ReviewState) {
 	if len(a) != len(b) {
 		t.Errorf("expected slice lengths to equal: %d != %d\nExpected: %v\nActual: %v", len(a), len(b), a, b)
 		return
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// defaultGraphQLURL is the endpoint of the Github GraphQL API.
+const defaultGraphQLURL = "https://api.github.com/graphql"
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphQL runs the query against the Github GraphQL API, decoding its data
+// into result. Responses with errors fail, even when they include data.
+func (r RepositoryClient) graphQL(query string, variables map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode graphql request: %w", err)
+	}
+	req, err := http.NewRequest("POST", r.graphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create graphql request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req.WithContext(context.TODO()))
+	if err != nil {
+		return fmt.Errorf("graphql request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("graphql request failed: %v", resp.Status)
+	}
+	var decoded graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return fmt.Errorf("failed to decode graphql response: %w", err)
+	}
+	if len(decoded.Errors) > 0 {
+		messages := make([]string, 0, len(decoded.Errors))
+		for _, e := range decoded.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("graphql query failed: %v", strings.Join(messages, "; "))
+	}
+	if err := json.Unmarshal(decoded.Data, result); err != nil {
+		return fmt.Errorf("failed to decode graphql data: %w", err)
+	}
+	return nil
+}
+
+const reviewThreadsQuery = `query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        nodes { isResolved }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+type reviewThreadsData struct {
+	Repository struct {
+		PullRequest struct {
+			ReviewThreads struct {
+				Nodes []struct {
+					IsResolved bool `json:"isResolved"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"reviewThreads"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+// UnresolvedReviewThreads returns the number of review threads on the pull
+// request that haven't been resolved. The REST API doesn't expose whether a
+// thread is resolved, so this uses the GraphQL API.
+func (r RepositoryClient) UnresolvedReviewThreads(number int) (int, error) {
+	variables := map[string]interface{}{
+		"owner":  r.owner,
+		"name":   r.name,
+		"number": number,
+	}
+	unresolved := 0
+	for {
+		var data reviewThreadsData
+		if err := r.graphQL(reviewThreadsQuery, variables, &data); err != nil {
+			return 0, fmt.Errorf("failed to list review threads: %w", err)
+		}
+		threads := data.Repository.PullRequest.ReviewThreads
+		for _, thread := range threads.Nodes {
+			if !thread.IsResolved {
+				unresolved++
+			}
+		}
+		if !threads.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = threads.PageInfo.EndCursor
+	}
+
+	return unresolved, nil
+}
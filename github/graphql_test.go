@@ -0,0 +1,88 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnresolvedReviewThreads(t *testing.T) {
+	var requests []graphQLRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		requests = append(requests, req)
+
+		if req.Variables["cursor"] == nil {
+			w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+				"nodes": [{"isResolved": true}, {"isResolved": false}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "abc"}
+			}}}}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"nodes": [{"isResolved": false}],
+			"pageInfo": {"hasNextPage": false, "endCursor": "def"}
+		}}}}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	unresolved, err := client.UnresolvedReviewThreads(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if unresolved != 2 {
+		t.Errorf("expected: 2, got: %d", unresolved)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got: %d", len(requests))
+	}
+	variables := requests[0].Variables
+	if variables["owner"] != "owner" || variables["name"] != "name" || variables["number"] != float64(7) {
+		t.Errorf("unexpected variables: %v", variables)
+	}
+	if requests[1].Variables["cursor"] != "abc" {
+		t.Errorf("expected cursor: %q, got: %v", "abc", requests[1].Variables["cursor"])
+	}
+}
+
+func TestUnresolvedReviewThreadsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		payload string
+	}{
+		{
+			name:    "GraphQL Error",
+			status:  http.StatusOK,
+			payload: `{"data": null, "errors": [{"message": "Could not resolve to a PullRequest"}]}`,
+		},
+		{
+			name:    "HTTP Error",
+			status:  http.StatusUnauthorized,
+			payload: `{"message": "Bad credentials"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.payload))
+			}))
+			defer server.Close()
+			client := newTestClient(t, server)
+
+			if _, err := client.UnresolvedReviewThreads(7); err == nil {
+				t.Fatalf("expected error, got: nil")
+			}
+		})
+	}
+}
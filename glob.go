@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether the slash separated name matches the pattern.
+// Segments are matched with path.Match, and a "**" segment matches zero or
+// more whole segments.
+func matchGlob(pattern, name string) (bool, error) {
+	return matchSegments(
+		strings.Split(strings.Trim(pattern, "/"), "/"),
+		strings.Split(strings.Trim(name, "/"), "/"),
+	)
+}
+
+func matchSegments(pattern, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated "**" segments, they match the same paths.
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true, nil
+			}
+			for i := range name {
+				matched, err := matchSegments(pattern, name[i:])
+				if err != nil || matched {
+					return matched, err
+				}
+			}
+			return false, nil
+		}
+
+		if len(name) == 0 {
+			return false, nil
+		}
+		matched, err := path.Match(pattern[0], name[0])
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// matchGitGlob matches name the way git matches .gitattributes patterns. A
+// pattern without a slash matches at any depth, otherwise it is relative to
+// the root of the repository.
+func matchGitGlob(pattern, name string) (bool, error) {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchGlob(pattern, name)
+}
+
+// matchGlobs evaluates the patterns in order against name, where a pattern
+// prefixed with "!" excludes names matched by the earlier patterns. A list
+// that starts with an exclusion matches everything it doesn't exclude.
+func matchGlobs(patterns []string, name string) (bool, error) {
+	matched := len(patterns) > 0 && strings.HasPrefix(patterns[0], "!")
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		ok, err := matchGlob(strings.TrimPrefix(pattern, "!"), name)
+		if err != nil {
+			return false, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		if ok {
+			matched = !negated
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "*.md", name: "README.md", expected: true},
+		{pattern: "*.md", name: "docs/README.md", expected: false},
+		{pattern: "docs/*", name: "docs/README.md", expected: true},
+		{pattern: "docs/*", name: "docs/api/README.md", expected: false},
+		{pattern: "docs/**", name: "docs/api/README.md", expected: true},
+		{pattern: "docs/**", name: "docs", expected: true},
+		{pattern: "**/*.go", name: "main.go", expected: true},
+		{pattern: "**/*.go", name: "github/client.go", expected: true},
+		{pattern: "db/**/migrations/*.sql", name: "db/migrations/0001.sql", expected: true},
+		{pattern: "db/**/migrations/*.sql", name: "db/app/v2/migrations/0001.sql", expected: true},
+		{pattern: "db/**/migrations/*.sql", name: "db/app/seeds/0001.sql", expected: false},
+		{pattern: "**/**/vendor/**", name: "vendor/gopkg.in/yaml.v3/yaml.go", expected: true},
+		{pattern: "file?.txt", name: "file1.txt", expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			actual, err := matchGlob(tc.pattern, tc.name)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMatchGlobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		expected bool
+	}{
+		{
+			name:     "Empty",
+			patterns: nil,
+			path:     "main.go",
+			expected: false,
+		},
+		{
+			name:     "Included",
+			patterns: []string{"**/*.md"},
+			path:     "docs/guide.md",
+			expected: true,
+		},
+		{
+			name:     "Excluded",
+			patterns: []string{"**/*.md", "!CHANGELOG.md"},
+			path:     "CHANGELOG.md",
+			expected: false,
+		},
+		{
+			name:     "Reincluded",
+			patterns: []string{"docs/**", "!docs/generated/**", "docs/generated/index.md"},
+			path:     "docs/generated/index.md",
+			expected: true,
+		},
+		{
+			name:     "Only Exclusions",
+			patterns: []string{"!vendor/**"},
+			path:     "main.go",
+			expected: true,
+		},
+		{
+			name:     "Only Exclusions Excluded",
+			patterns: []string{"!vendor/**"},
+			path:     "vendor/modules.txt",
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := matchGlobs(tc.patterns, tc.path)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMatchGlobsInvalid(t *testing.T) {
+	_, err := matchGlobs([]string{"docs/[", "!docs/**"}, "docs/index.md")
+	if err == nil {
+		t.Fatalf("expected error for invalid pattern, got: nil")
+	}
+}
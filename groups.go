@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// groupPredecessors returns the rules listed before the rule in each group
+// containing it, sorted. They take priority over the rule.
+func (c labelerConfig) groupPredecessors(rule string) []string {
+	var predecessors []string
+	for _, members := range c.Groups {
+		for i, member := range members {
+			if member != rule {
+				continue
+			}
+			for _, predecessor := range members[:i] {
+				if !contains(predecessors, predecessor) {
+					predecessors = append(predecessors, predecessor)
+				}
+			}
+		}
+	}
+	sort.Strings(predecessors)
+	return predecessors
+}
+
+// validateGroups checks that every group member is a rule listed once.
+func (c labelerConfig) validateGroups() error {
+	for name, members := range c.Groups {
+		for i, member := range members {
+			if _, ok := c.Labels[member]; !ok {
+				return fmt.Errorf("group %q lists %q, which has no rule", name, member)
+			}
+			if contains(members[:i], member) {
+				return fmt.Errorf("group %q lists %q more than once", name, member)
+			}
+		}
+	}
+	return nil
+}
+
+// outranked reports whether a rule listed before the rule in one of its
+// groups was applied.
+func (c labelerConfig) outranked(rule string, applied map[string]bool) bool {
+	for _, predecessor := range c.groupPredecessors(rule) {
+		if applied[predecessor] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLabelsForPRStateGroups(t *testing.T) {
+	var config labelerConfig
+	err := yaml.Unmarshal([]byte(`
+groups:
+  review: [Changes Requested, Code Review Approved, Awaiting Code Review]
+Awaiting Code Review:
+  draft: false
+Changes Requested:
+  changes_requested: true
+Code Review Approved:
+  approved: true
+Ready To Merge:
+  requires_labels: [Code Review Approved]
+`), &config)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name:     "Awaiting",
+			state:    prState{labels: []string{"Code Review Approved"}},
+			expected: []string{"Awaiting Code Review"},
+		},
+		{
+			name:     "Approved Outranks Awaiting",
+			state:    prState{approved: true},
+			expected: []string{"Code Review Approved", "Ready To Merge"},
+		},
+		{
+			name:     "Changes Requested Outranks Approved",
+			state:    prState{labels: []string{"Code Review Approved", "Ready To Merge"}, approved: true, changesRequested: true},
+			expected: []string{"Changes Requested"},
+		},
+		{
+			name:     "None Match",
+			state:    prState{labels: []string{"Awaiting Code Review"}, draft: true},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   labelerConfig
+		expected string
+	}{
+		{
+			name: "Unknown Rule",
+			config: labelerConfig{
+				Groups: map[string][]string{"review": {"Approved", "Aproved"}},
+				Labels: map[string]labelConditions{"Approved": {}},
+			},
+			expected: `group "review" lists "Aproved", which has no rule`,
+		},
+		{
+			name: "Duplicate Rule",
+			config: labelerConfig{
+				Groups: map[string][]string{"review": {"Approved", "Approved"}},
+				Labels: map[string]labelConditions{"Approved": {}},
+			},
+			expected: `group "review" lists "Approved" more than once`,
+		},
+		{
+			name: "Cycle With Priority",
+			config: labelerConfig{
+				Groups: map[string][]string{"review": {"Approved", "Awaiting"}},
+				Labels: map[string]labelConditions{
+					"Approved": {AbsentLabels: []string{"Awaiting"}},
+					"Awaiting": {},
+				},
+			},
+			expected: "Approved -> Awaiting -> Approved",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.config.ruleOrder()
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected error containing: %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import "sort"
+
+type issueLabelEditor interface {
+	EditLabelsForIssue(repo string, number int, add, remove []string) error
+}
+
+// issueLabelChanges returns the labels to add to and remove from an issue so
+// it carries the issue labels mapped from the pull request's labels. Pull
+// requests closed without merging leave none of the issue labels behind.
+func (c labelerConfig) issueLabelChanges(state prState, labels []string, issue linkedIssue) (add, remove []string) {
+	abandoned := state.closed && !state.merged
+
+	wanted := make(map[string]bool, len(c.IssueLabels))
+	for prLabel, issueLabel := range c.IssueLabels {
+		wanted[issueLabel] = wanted[issueLabel] || (!abandoned && contains(labels, prLabel))
+	}
+
+	for label, want := range wanted {
+		has := contains(issue.labels, label)
+		if want && !has {
+			add = append(add, label)
+		}
+		if !want && has {
+			remove = append(remove, label)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+	return add, remove
+}
+
+// mirrorIssueLabels applies the issue labels mapped from the pull request's
+// labels to the issues it closes, without touching their other labels.
+func (c labelerConfig) mirrorIssueLabels(client issueLabelEditor, state prState, labels []string) error {
+	for _, issue := range state.linkedIssues {
+		if !issue.closing {
+			continue
+		}
+		add, remove := c.issueLabelChanges(state, labels, issue)
+		if len(add) == 0 && len(remove) == 0 {
+			continue
+		}
+		if err := client.EditLabelsForIssue(issue.repo, issue.number, add, remove); err != nil {
+			return err
+		}
+	}
+	return nil
+}
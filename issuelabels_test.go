@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fakeIssueLabelEditor struct {
+	edits []string
+}
+
+func (e *fakeIssueLabelEditor) EditLabelsForIssue(repo string, number int, add, remove []string) error {
+	e.edits = append(e.edits, fmt.Sprintf("%v#%d +%v -%v", repo, number, add, remove))
+	return nil
+}
+
+func TestMirrorIssueLabels(t *testing.T) {
+	config := labelerConfig{IssueLabels: map[string]string{
+		"Awaiting Code Review": "status/in-review",
+		"Code Review Approved": "status/approved",
+	}}
+	issues := []linkedIssue{
+		{
+			issueReference: issueReference{number: 12, closing: true},
+			labels:         []string{"bug", "status/approved"},
+		},
+		{
+			issueReference: issueReference{repo: "owner/other", number: 45, closing: true},
+			labels:         []string{"status/in-review"},
+		},
+		{
+			// Only named by the branch, so the pull request doesn't close it.
+			issueReference: issueReference{number: 123},
+			labels:         []string{"status/approved"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		state    prState
+		labels   []string
+		expected []string
+	}{
+		{
+			name:   "Open",
+			state:  prState{linkedIssues: issues},
+			labels: []string{"Bug", "Awaiting Code Review"},
+			expected: []string{
+				"#12 +[status/in-review] -[status/approved]",
+			},
+		},
+		{
+			name:   "Merged",
+			state:  prState{linkedIssues: issues, closed: true, merged: true},
+			labels: []string{"Code Review Approved"},
+			expected: []string{
+				"owner/other#45 +[status/approved] -[status/in-review]",
+			},
+		},
+		{
+			name:   "Closed Without Merging",
+			state:  prState{linkedIssues: issues, closed: true},
+			labels: []string{"Code Review Approved"},
+			expected: []string{
+				"#12 +[] -[status/approved]",
+				"owner/other#45 +[] -[status/in-review]",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			editor := &fakeIssueLabelEditor{}
+			if err := config.mirrorIssueLabels(editor, tc.state, tc.labels); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqual(t, tc.expected, editor.edits)
+		})
+	}
+}
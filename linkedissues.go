@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MTIConnect/labeler-action/github"
+)
+
+// closingKeywordRegexp finds the issues a pull request body closes with one of
+// Github's closing keywords, e.g. "Fixes #123" or "closes owner/repo#45".
+var closingKeywordRegexp = regexp.MustCompile(
+	`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+([\w.-]+/[\w.-]+)?#(\d+)\b`,
+)
+
+// branchIssueRegexp finds an issue number leading a segment of a branch name
+// and followed by a dash or underscore, e.g. "feature/123-foo" or "123_foo".
+// Bare numbers such as "release/2024" aren't issues.
+var branchIssueRegexp = regexp.MustCompile(`(?:^|/)(\d+)[-_]`)
+
+// issueReference is an issue of the repository, or of repo when it's set.
+// Closing references are made with a closing keyword, so merging the pull
+// request closes the issue.
+type issueReference struct {
+	repo    string
+	number  int
+	closing bool
+}
+
+// linkedIssue is an issue the pull request references, with its labels.
+type linkedIssue struct {
+	issueReference
+	labels []string
+}
+
+// linkedIssueReferences returns the issues closed by the body and the issue
+// named by the branch, without duplicates. References naming the pull
+// request's own repository, repo, are the same as those without one.
+func linkedIssueReferences(body, branch, repo string) []issueReference {
+	var refs []issueReference
+	add := func(ref, number string, closing bool) {
+		n, err := strconv.Atoi(number)
+		if err != nil || n == 0 {
+			return
+		}
+		if strings.EqualFold(ref, repo) {
+			ref = ""
+		}
+		for i := range refs {
+			if strings.EqualFold(refs[i].repo, ref) && refs[i].number == n {
+				refs[i].closing = refs[i].closing || closing
+				return
+			}
+		}
+		refs = append(refs, issueReference{repo: ref, number: n, closing: closing})
+	}
+
+	for _, match := range closingKeywordRegexp.FindAllStringSubmatch(body, -1) {
+		add(match[1], match[2], true)
+	}
+	if match := branchIssueRegexp.FindStringSubmatch(branch); match != nil {
+		add("", match[1], false)
+	}
+	return refs
+}
+
+type issueGetter interface {
+	Issue(repo string, number int) (github.Issue, error)
+}
+
+// loadLinkedIssues fetches the referenced issues. References to pull requests
+// or missing issues are skipped.
+func loadLinkedIssues(client issueGetter, refs []issueReference) ([]linkedIssue, error) {
+	var issues []linkedIssue
+	for _, ref := range refs {
+		issue, err := client.Issue(ref.repo, ref.number)
+		if errors.Is(err, github.ErrIssueNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, linkedIssue{issueReference: ref, labels: issue.Labels})
+	}
+	return issues, nil
+}
+
+// usesLinkedIssues reports whether labels are inherited from or mirrored onto
+// linked issues, or any rule has a linked_issue condition.
+func (c labelerConfig) usesLinkedIssues() bool {
+	if len(c.InheritLabels) > 0 || len(c.IssueLabels) > 0 {
+		return true
+	}
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return conditions.LinkedIssue != nil || conditions.If.uses("linked_issue")
+	})
+}
+
+// inheritLabels copies the labels of the linked issues matching the patterns
+// onto the pull request. Labels are never removed, as an inherited label
+// can't be told apart from one added by hand.
+func inheritLabels(labels []string, patterns []string, issues []linkedIssue) ([]string, error) {
+	for _, issue := range issues {
+		for _, label := range issue.labels {
+			matched, err := matchGlobs(patterns, label)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				labels = addLabel(labels, label)
+			}
+		}
+	}
+	return labels, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MTIConnect/labeler-action/github"
+)
+
+func TestLinkedIssueReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		branch   string
+		expected []issueReference
+	}{
+		{
+			name:     "None",
+			body:     "Refactors the client, see #12.",
+			branch:   "refactor-client",
+			expected: nil,
+		},
+		{
+			name:   "Closing Keywords",
+			body:   "Fixes #12\nThis also closes: owner/repo#45 and RESOLVED #7.",
+			branch: "master",
+			expected: []issueReference{
+				{number: 12, closing: true},
+				{repo: "owner/repo", number: 45, closing: true},
+				{number: 7, closing: true},
+			},
+		},
+		{
+			name:     "Branch Name",
+			body:     "",
+			branch:   "feature/123-foo",
+			expected: []issueReference{{number: 123, closing: false}},
+		},
+		{
+			name:     "Branch Name Without Issue",
+			body:     "",
+			branch:   "release/v1.2",
+			expected: nil,
+		},
+		{
+			name:     "Branch Name With Bare Number",
+			body:     "",
+			branch:   "release/2024",
+			expected: nil,
+		},
+		{
+			name:     "Duplicates",
+			body:     "Fix #123",
+			branch:   "123_foo",
+			expected: []issueReference{{number: 123, closing: true}},
+		},
+		{
+			name:     "Duplicates Naming Repository",
+			body:     "See #12, fixes MTIConnect/Labeler-Action#12",
+			branch:   "12-foo",
+			expected: []issueReference{{number: 12, closing: true}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := linkedIssueReferences(tc.body, tc.branch, "MTIConnect/labeler-action")
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("expected: %v, got: %v", tc.expected, actual)
+			}
+			for i := range tc.expected {
+				if actual[i] != tc.expected[i] {
+					t.Errorf("expected: %v, got: %v", tc.expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestLabelsForPRStateLinkedIssues(t *testing.T) {
+	falseCheck := false
+	client := fakePRClient{
+		issues: map[string]github.Issue{
+			"#123":           {Number: 123, Labels: []string{"priority/high", "area/api", "bug"}},
+			"owner/other#45": {Number: 45, Labels: []string{"area/billing"}},
+		},
+	}
+	config := labelerConfig{
+		InheritLabels: []string{"priority/*", "area/*"},
+		Labels: map[string]labelConditions{
+			"No Linked Issue": {
+				LinkedIssue: &falseCheck,
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		payload  string
+		expected []string
+	}{
+		{
+			name:     "Inherits",
+			payload:  `{"pull_request": {"number": 1, "body": "Closes owner/other#45", "head": {"ref": "feature/123-foo"}, "labels": [{"name": "area/ui"}, {"name": "WIP"}]}}`,
+			expected: []string{"area/ui", "WIP", "priority/high", "area/api", "area/billing"},
+		},
+		{
+			name:     "Missing Issue",
+			payload:  `{"pull_request": {"number": 1, "body": "Fixes #999", "head": {"ref": "bump-deps"}, "labels": [{"name": "priority/low"}]}}`,
+			expected: []string{"priority/low", "No Linked Issue"},
+		},
+		{
+			name:     "Same Repository",
+			payload:  `{"pull_request": {"number": 1, "body": "Fixes owner/repo#123", "head": {"ref": "bump-deps"}, "base": {"repo": {"full_name": "owner/repo"}}}}`,
+			expected: []string{"priority/high", "area/api"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			states, err := prStatesFromEvent(client, config, "pull_request", []byte(tc.payload))
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			actual, err := config.labelsForPRState(states[0])
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
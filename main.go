@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"strings"
 
 	gh "github.com/google/go-github/v29/github"
 	"gopkg.in/yaml.v3"
@@ -37,93 +38,545 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal labeler config: %w", err)
 	}
+	if _, err := config.ruleOrder(); err != nil {
+		return fmt.Errorf("invalid labeler config: %w", err)
+	}
+	if err := config.Reviews.validate(); err != nil {
+		return fmt.Errorf("invalid labeler config: %w", err)
+	}
+	if err := config.validateTemplates(); err != nil {
+		return fmt.Errorf("invalid labeler config: %w", err)
+	}
+	if config.Owners != nil {
+		if err := config.Owners.validate(); err != nil {
+			return fmt.Errorf("invalid labeler config: %w", err)
+		}
+	}
 	log.Println("Loaded action config:", os.Getenv("INPUT_CONFIG_PATH"))
 
 	// Get event details for processing.
 	eventName := os.Getenv("GITHUB_EVENT_NAME")
 	payload, err := ioutil.ReadFile(os.Getenv("GITHUB_EVENT_PATH"))
 	if err != nil {
-		return fmt.Errorf("failed to read event payload: %w", payload)
+		return fmt.Errorf("failed to read event payload: %w", err)
 	}
 
-	// Get PR State using event details.
-	state, err := prStateFromEvent(repo, eventName, payload)
+	// Get PR States using event details.
+	states, err := prStatesFromEvent(repo, config, eventName, payload)
 	if err != nil {
 		return fmt.Errorf("failed to process state from webhook data: %w", err)
 	}
-	log.Println("Calculated pr state:", state)
 
-	// Evaluate the config rules.
-	labels, err := config.labelsForPRState(state)
-	if err != nil {
-		return fmt.Errorf("failed to evaluate labels: %w", err)
-	}
-	log.Println("Current Labels:", state.labels)
-	log.Println("Calculated Labels:", labels)
+	for _, state := range states {
+		log.Println("Calculated pr state:", state)
+
+		// Evaluate the config rules.
+		labels, err := config.labelsForPRState(state)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate labels: %w", err)
+		}
+		log.Println("Current Labels:", state.labels)
+		log.Println("Calculated Labels:", labels)
 
-	// Replace labels after configured label operations.
-	if !stringSlicesEqual(state.labels, labels) {
-		err = repo.ReplaceLabelsForIssue(state.issueNumber, labels)
+		// Update labels after configured label operations.
+		err = config.writeLabels(repo, state, labels)
 		if err != nil {
-			return fmt.Errorf("failed to replace labels on pull request: %w", err)
+			return fmt.Errorf("failed to update labels on pull request: %w", err)
+		}
+
+		// Mirror the labels onto the issues the pull request closes.
+		if len(config.IssueLabels) > 0 {
+			err = config.mirrorIssueLabels(repo, state, labels)
+			if err != nil {
+				return fmt.Errorf("failed to mirror labels onto issues: %w", err)
+			}
 		}
 	}
 
 	return nil
 }
 
-type labelerConfig map[string]struct {
-	Draft            *bool
-	BranchName       string `yaml:"branch_name"`
-	Title            string
-	ChangesRequested *bool `yaml:"changes_requested"`
-	Approved         *bool
+type labelerConfig struct {
+	Size                 *sizeRule
+	Owners               *ownersRule
+	ApprovalRequirements []approvalRequirement `yaml:"approval_requirements"`
+	WaitingOn            *waitingOnRule        `yaml:"waiting_on"`
+	InheritLabels        []string              `yaml:"inherit_labels"`
+	IssueLabels          map[string]string     `yaml:"issue_labels"`
+	Groups               map[string][]string
+	ManagedPrefixes      []string `yaml:"managed_prefixes"`
+	IgnoreChecks         []string `yaml:"ignore_checks"`
+	Reviews              reviewSettings
+	Labels               map[string]labelConditions `yaml:",inline"`
 }
 
-func (c labelerConfig) labelsForPRState(state prState) ([]string, error) {
-	labels := append([]string(nil), state.labels...)
-	for label, conditions := range c {
-		if conditions.Approved != nil &&
-			*conditions.Approved != state.approved {
-			labels = removeLabel(labels, label)
-			continue
+type labelConditions struct {
+	Draft                  *bool
+	BranchName             string `yaml:"branch_name"`
+	BaseBranch             string `yaml:"base_branch"`
+	Title                  string
+	Body                   string
+	Checklist              *checklistCondition
+	ChangesRequested       *bool `yaml:"changes_requested"`
+	Approved               *bool
+	Approvals              *comparison
+	IgnoreStaleApprovals   *bool       `yaml:"ignore_stale_approvals"`
+	ApprovedOnLatestCommit *bool       `yaml:"approved_on_latest_commit"`
+	CodeownerApproved      *bool       `yaml:"codeowner_approved"`
+	ReviewRequested        *bool       `yaml:"review_requested"`
+	RequestedTeam          string      `yaml:"requested_team"`
+	AllReviewersResponded  *bool       `yaml:"all_requested_reviewers_responded"`
+	ChangesRequests        *comparison `yaml:"changes_requests"`
+	UnresolvedThreads      *comparison `yaml:"unresolved_threads"`
+	Mergeable              *bool
+	BehindBase             *bool `yaml:"behind_base"`
+	LinkedIssue            *bool `yaml:"linked_issue"`
+
+	// If is an expression over the pull request state.
+	If *expression
+
+	// RequiresLabels and AbsentLabels test the labels applied by the rules
+	// evaluated before, or already on the pull request.
+	RequiresLabels []string `yaml:"requires_labels"`
+	AbsentLabels   []string `yaml:"absent_labels"`
+
+	// All, Any and Not nest condition blocks that all, at least one or none
+	// of must match.
+	All            []labelConditions
+	Any            []labelConditions
+	Not            *labelConditions
+	Files          *filesCondition
+	Additions      *comparison
+	Deletions      *comparison
+	ChangedFiles   *comparison `yaml:"changed_files"`
+	Commits        *comparison
+	Comments       *comparison
+	ReviewComments *comparison `yaml:"review_comments"`
+	Author         *authorCondition
+	AuthorTeam     []string `yaml:"author_team"`
+	Checks         map[string]string
+	ChecksAll      string `yaml:"checks_all"`
+	ChecksAny      string `yaml:"checks_any"`
+}
+
+// authorCondition tests the user that opened the pull request. Logins and
+// associations are compared case insensitively.
+type authorCondition struct {
+	Login       string
+	Logins      []string
+	IsBot       *bool    `yaml:"is_bot"`
+	Association []string `yaml:"author_association"`
+}
+
+func (c authorCondition) matches(state prState) (bool, error) {
+	if c.Login != "" {
+		matched, err := regexp.MatchString(c.Login, state.author)
+		if err != nil {
+			return false, fmt.Errorf("failed to compile author login regexp: %w", err)
+		}
+		if !matched {
+			return false, nil
 		}
+	}
 
-		if conditions.ChangesRequested != nil &&
-			*conditions.ChangesRequested != state.changesRequested {
-			labels = removeLabel(labels, label)
-			continue
+	if len(c.Logins) > 0 && !containsFold(c.Logins, state.author) {
+		return false, nil
+	}
+
+	if c.IsBot != nil && *c.IsBot != state.authorIsBot {
+		return false, nil
+	}
+
+	if len(c.Association) > 0 && !containsFold(c.Association, state.authorAssociation) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// metricsMatch reports whether every configured numeric condition holds.
+func (c labelConditions) metricsMatch(state prState) bool {
+	metrics := []struct {
+		condition *comparison
+		value     int
+	}{
+		{c.Additions, state.additions},
+		{c.Deletions, state.deletions},
+		{c.ChangedFiles, state.changedFiles},
+		{c.Commits, state.commits},
+		{c.Comments, state.comments},
+		{c.ReviewComments, state.reviewComments},
+		{c.Approvals, state.approvals},
+		{c.ChangesRequests, state.changesRequests},
+		{c.UnresolvedThreads, state.unresolvedThreads},
+	}
+	for _, metric := range metrics {
+		if metric.condition != nil && !metric.condition.matches(metric.value) {
+			return false
 		}
+	}
+	return true
+}
 
-		if conditions.Draft != nil &&
-			*conditions.Draft != state.draft {
-			labels = removeLabel(labels, label)
-			continue
+// countNames are the expression variables of the counts of a pull request.
+var countNames = []string{"additions", "deletions", "changed_files", "commits", "comments", "review_comments"}
+
+// usesCounts reports whether any rule tests a count of the pull request.
+func (c labelerConfig) usesCounts() bool {
+	return c.anyConditions(func(conditions labelConditions) bool {
+		for _, condition := range []*comparison{conditions.Additions, conditions.Deletions, conditions.ChangedFiles,
+			conditions.Commits, conditions.Comments, conditions.ReviewComments} {
+			if condition != nil {
+				return true
+			}
+		}
+		for _, name := range countNames {
+			if conditions.If.uses(name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// usesFiles reports whether the changed files are needed, by a files
+// condition or expression, or by the size, owners or approval requirement
+// rules.
+func (c labelerConfig) usesFiles() bool {
+	if c.Size != nil || c.Owners != nil || len(c.ApprovalRequirements) > 0 || c.usesCodeownerApproved() {
+		return true
+	}
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return conditions.Files != nil || conditions.If.uses("files")
+	})
+}
+
+// filesCondition tests the files changed by the pull request. Only files
+// with one of the listed statuses are considered when Status is set.
+type filesCondition struct {
+	Any    []string
+	All    []string
+	Status []string
+}
+
+func (c filesCondition) matches(files []changedFile) (bool, error) {
+	var considered []changedFile
+	for _, file := range files {
+		if len(c.Status) == 0 || containsFold(c.Status, file.status) {
+			considered = append(considered, file)
 		}
+	}
+	if len(considered) == 0 {
+		return false, nil
+	}
 
-		if conditions.Title != "" {
-			matched, err := regexp.MatchString(conditions.Title, state.title)
+	if len(c.Any) > 0 {
+		found := false
+		for _, file := range considered {
+			matched, err := matchGlobs(c.Any, file.path)
 			if err != nil {
-				return nil, fmt.Errorf("failed to compile title regexp: %w", err)
+				return false, err
 			}
-			if !matched {
-				labels = removeLabel(labels, label)
-				continue
+			if matched {
+				found = true
+				break
 			}
 		}
+		if !found {
+			return false, nil
+		}
+	}
 
-		if conditions.BranchName != "" {
-			matched, err := regexp.MatchString(conditions.BranchName, state.branchName)
+	if len(c.All) > 0 {
+		for _, file := range considered {
+			matched, err := matchGlobs(c.All, file.path)
 			if err != nil {
-				return nil, fmt.Errorf("failed to compile branch name regexp: %w", err)
+				return false, err
 			}
 			if !matched {
-				labels = removeLabel(labels, label)
-				continue
+				return false, nil
 			}
 		}
+	}
+
+	return true, nil
+}
+
+// matches reports whether every configured condition holds for the state.
+// Named groups captured by the regexp conditions are stored in captures.
+func (c labelConditions) matches(state prState, captures map[string]string) (bool, error) {
+	if c.IgnoreStaleApprovals != nil {
+		state.approvals = state.allApprovals
+		if *c.IgnoreStaleApprovals {
+			state.approvals = state.currentApprovals
+		}
+		state.approved = state.approvals > 0
+	}
+
+	if c.ApprovedOnLatestCommit != nil &&
+		*c.ApprovedOnLatestCommit != (state.currentApprovals > 0) {
+		return false, nil
+	}
+
+	if c.CodeownerApproved != nil &&
+		*c.CodeownerApproved != state.codeownerApproved {
+		return false, nil
+	}
+
+	reviewRequested := len(state.requestedReviewers) > 0 || len(state.requestedTeams) > 0
+	if c.ReviewRequested != nil &&
+		*c.ReviewRequested != reviewRequested {
+		return false, nil
+	}
+
+	requestedTeam := c.RequestedTeam[strings.LastIndex(c.RequestedTeam, "/")+1:]
+	if requestedTeam != "" &&
+		!containsFold(state.requestedTeams, requestedTeam) {
+		return false, nil
+	}
+
+	if c.AllReviewersResponded != nil &&
+		*c.AllReviewersResponded != (!reviewRequested && state.reviewed) {
+		return false, nil
+	}
+
+	if c.Mergeable != nil &&
+		*c.Mergeable != state.mergeable {
+		return false, nil
+	}
+
+	if c.BehindBase != nil &&
+		*c.BehindBase != state.behindBase {
+		return false, nil
+	}
+
+	if c.LinkedIssue != nil &&
+		*c.LinkedIssue != (len(state.linkedIssues) > 0) {
+		return false, nil
+	}
+
+	if c.Approved != nil &&
+		*c.Approved != state.approved {
+		return false, nil
+	}
+
+	if c.ChangesRequested != nil &&
+		*c.ChangesRequested != state.changesRequested {
+		return false, nil
+	}
+
+	if c.Draft != nil &&
+		*c.Draft != state.draft {
+		return false, nil
+	}
+
+	if !c.metricsMatch(state) {
+		return false, nil
+	}
+
+	if c.If != nil && !c.If.matches(state) {
+		return false, nil
+	}
+
+	for _, label := range c.RequiresLabels {
+		if !contains(state.appliedLabels, label) {
+			return false, nil
+		}
+	}
+
+	for _, label := range c.AbsentLabels {
+		if contains(state.appliedLabels, label) {
+			return false, nil
+		}
+	}
+
+	if c.Title != "" {
+		matched, err := matchCaptures(c.Title, state.title, captures)
+		if err != nil {
+			return false, withConditionPath("title", fmt.Errorf("failed to compile title regexp: %w", err))
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if c.BranchName != "" {
+		matched, err := matchCaptures(c.BranchName, state.branchName, captures)
+		if err != nil {
+			return false, withConditionPath("branch_name", fmt.Errorf("failed to compile branch name regexp: %w", err))
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if c.Body != "" {
+		matched, err := matchCaptures(c.Body, state.body, captures)
+		if err != nil {
+			return false, withConditionPath("body", fmt.Errorf("failed to compile body regexp: %w", err))
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if c.Checklist != nil {
+		matched, err := c.Checklist.matches(state.checklist)
+		if err != nil {
+			return false, withConditionPath("checklist", err)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if c.BaseBranch != "" {
+		matched, err := matchCaptures(c.BaseBranch, state.baseBranch, captures)
+		if err != nil {
+			return false, withConditionPath("base_branch", fmt.Errorf("failed to compile base branch regexp: %w", err))
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if c.Author != nil {
+		matched, err := c.Author.matches(state)
+		if err != nil {
+			return false, withConditionPath("author", err)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if len(c.AuthorTeam) > 0 {
+		member := false
+		for _, team := range c.AuthorTeam {
+			member = member || state.authorTeams[team]
+		}
+		if !member {
+			return false, nil
+		}
+	}
+
+	if !c.checksMatch(state.checks) {
+		return false, nil
+	}
 
-		labels = addLabel(labels, label)
+	if c.Files != nil {
+		matched, err := c.Files.matches(state.files)
+		if err != nil {
+			return false, withConditionPath("files", fmt.Errorf("failed to match changed files: %w", err))
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	return c.combinatorsMatch(state, captures)
+}
+
+// authorTeams returns every team referenced by an author_team condition.
+func (c labelerConfig) authorTeams() []string {
+	var teams []string
+	c.anyConditions(func(conditions labelConditions) bool {
+		for _, team := range conditions.AuthorTeam {
+			if !contains(teams, team) {
+				teams = append(teams, team)
+			}
+		}
+		return false
+	})
+	return teams
+}
+
+func (c labelerConfig) labelsForPRState(state prState) ([]string, error) {
+	if len(c.ManagedPrefixes) > 0 {
+		return c.managedLabelsForPRState(state)
+	}
+	return c.applyRules(state, state.labels)
+}
+
+// applyRules applies the rules to the labels, returning the resulting labels.
+func (c labelerConfig) applyRules(state prState, labels []string) ([]string, error) {
+	labels = append([]string(nil), labels...)
+
+	// Reserved rules only touch their own labels, so they're applied first
+	// for rules to reference.
+	if c.Size != nil {
+		var err error
+		labels, err = c.Size.apply(labels, state.files)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate size labels: %w", err)
+		}
+	}
+
+	if c.Owners != nil {
+		var err error
+		labels, err = c.Owners.apply(labels, state.owners)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate owner labels: %w", err)
+		}
+	}
+
+	for _, requirement := range c.ApprovalRequirements {
+		var err error
+		labels, err = requirement.apply(labels, state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate approval requirement: %w", err)
+		}
+	}
+
+	if c.WaitingOn != nil {
+		labels = c.WaitingOn.apply(labels, state.waitingOn)
+	}
+
+	if len(c.InheritLabels) > 0 {
+		var err error
+		labels, err = inheritLabels(labels, c.InheritLabels, state.linkedIssues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inherit labels: %w", err)
+		}
+	}
+
+	// Rules see the labels applied by the rules evaluated before them, so
+	// they're evaluated after the rules whose labels they reference.
+	order, err := c.ruleOrder()
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(order))
+	for _, label := range order {
+		conditions := c.Labels[label]
+		state.appliedLabels = labels
+
+		captures := make(map[string]string)
+		matched, err := conditions.matches(state, captures)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate rule: %w", withConditionPath(label, err))
+		}
+		// Only the first matching rule of a group is applied.
+		matched = matched && !c.outranked(label, applied)
+		applied[label] = matched
+
+		if isLabelTemplate(label) {
+			subpatterns, err := conditions.captureSubpatterns()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse rule regexes: %w", withConditionPath(label, err))
+			}
+			labels, err = applyLabelTemplate(labels, label, subpatterns, matched, captures)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render label %q: %w", label, err)
+			}
+			continue
+		}
+
+		if matched {
+			labels = addLabel(labels, label)
+		} else {
+			labels = removeLabel(labels, label)
+		}
 	}
 
 	return labels, nil
@@ -132,38 +585,217 @@ func (c labelerConfig) labelsForPRState(state prState) ([]string, error) {
 type prState struct {
 	issueNumber int
 	labels      []string
+	// appliedLabels are the labels after the rules evaluated so far.
+	appliedLabels []string
 
 	draft            bool
+	closed           bool
+	merged           bool
 	branchName       string
+	baseBranch       string
 	title            string
+	body             string
+	checklist        []checklistItem
 	changesRequested bool
 	approved         bool
+	approvals        int
+	allApprovals     int
+	currentApprovals int
+	changesRequests  int
+	approvers        []string
+	reviewed         bool
+	// unresolvedThreads is only loaded when a rule tests it.
+	unresolvedThreads int
+	teamApprovers     map[string][]string
+
+	requestedReviewers []string
+	requestedTeams     []string
+	// waitingOn is who has to act next, the "author" or the "reviewer".
+	waitingOn string
+
+	codeownerApproved bool
+	owners            []string
+	files             []changedFile
+
+	author            string
+	authorIsBot       bool
+	authorAssociation string
+	authorTeams       map[string]bool
+
+	additions      int
+	deletions      int
+	changedFiles   int
+	commits        int
+	comments       int
+	reviewComments int
+
+	headSHA string
+	checks  map[string]string
+
+	// mergeable is false when the pull request has conflicts with its base,
+	// and behindBase when the base has commits the head doesn't include.
+	// Both are only loaded when a rule tests them.
+	mergeable  bool
+	behindBase bool
+
+	// linkedIssues are the issues the body closes or the branch names.
+	linkedIssues []linkedIssue
+}
+
+type changedFile struct {
+	path      string
+	status    string
+	additions int
+	deletions int
+	generated bool
 }
 
 type reviewsLister interface {
 	PullRequestReviews(int) ([]github.Review, error)
 }
 
-func prStateFromEvent(client reviewsLister, eventName string, payload []byte) (prState, error) {
+type filesLister interface {
+	ListFiles(int) ([]github.PullRequestFile, error)
+}
+
+type pullRequestGetter interface {
+	PullRequest(int) (*gh.PullRequest, error)
+	PullRequestsForHead(string) ([]int, error)
+}
+
+type checksLister interface {
+	CombinedStatus(string) ([]github.CheckResult, error)
+	ListCheckRuns(string) ([]github.CheckResult, error)
+}
+
+type prClient interface {
+	reviewsLister
+	filesLister
+	pullRequestGetter
+	checksLister
+	teamMemberChecker
+	fileDownloader
+	permissionGetter
+	timelineLister
+	reviewThreadsCounter
+	mergeabilityChecker
+	issueGetter
+}
+
+// prStatesFromEvent returns the state of every pull request the event relates to.
+func prStatesFromEvent(client prClient, config labelerConfig, eventName string, payload []byte) ([]prState, error) {
+	prs, err := pullRequestsFromEvent(client, eventName, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave generated and vendored files out of size calculations.
+	var attributes gitAttributes
+	if config.Size != nil {
+		attributes, err = loadGitAttributes(client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load git attributes: %w", err)
+		}
+	}
+
+	states := make([]prState, 0, len(prs))
+	for _, pr := range prs {
+		state, err := prStateFromPullRequest(client, config, pr)
+		if err != nil {
+			return nil, err
+		}
+		state.files = markGeneratedFiles(state.files, attributes)
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// pullRequestsFromEvent returns the pull requests the event relates to. Check
+// and status events only reference pull requests, so they are fetched.
+func pullRequestsFromEvent(client pullRequestGetter, eventName string, payload []byte) ([]*gh.PullRequest, error) {
 	event, err := gh.ParseWebHook(eventName, payload)
 	if err != nil {
-		return prState{}, fmt.Errorf("failed to parse event data: %w", err)
+		return nil, fmt.Errorf("failed to parse event data: %w", err)
 	}
 
-	prGetter, ok := event.(interface {
-		GetPullRequest() *gh.PullRequest
-	})
-	if !ok {
-		return prState{}, fmt.Errorf("event didn't relate to pull request")
+	var numbers []int
+	switch event := event.(type) {
+	case *gh.CheckRunEvent:
+		numbers = pullRequestNumbers(event.GetCheckRun().PullRequests)
+	case *gh.CheckSuiteEvent:
+		numbers = pullRequestNumbers(event.GetCheckSuite().PullRequests)
+	case *gh.StatusEvent:
+		numbers, err = client.PullRequestsForHead(event.GetSHA())
+		if err != nil {
+			return nil, fmt.Errorf("couldn't find pull requests for commit: %w", err)
+		}
+	case interface{ GetPullRequest() *gh.PullRequest }:
+		return []*gh.PullRequest{event.GetPullRequest()}, nil
+	default:
+		return nil, fmt.Errorf("event didn't relate to pull request")
 	}
-	pr := prGetter.GetPullRequest()
+
+	prs := make([]*gh.PullRequest, 0, len(numbers))
+	for _, number := range numbers {
+		pr, err := client.PullRequest(number)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get pull request #%d: %w", number, err)
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func pullRequestNumbers(prs []*gh.PullRequest) []int {
+	numbers := make([]int, 0, len(prs))
+	for _, pr := range prs {
+		numbers = append(numbers, pr.GetNumber())
+	}
+	return numbers
+}
+
+func prStateFromPullRequest(client prClient, config labelerConfig, pr *gh.PullRequest) (prState, error) {
+	// The pull request of review and comment events omits its counts, so
+	// the full pull request is fetched when the rules test them.
+	if pr.Additions == nil && config.usesCounts() {
+		full, err := client.PullRequest(pr.GetNumber())
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't get pull request counts: %w", err)
+		}
+		pr = full
+	}
+
 	state := prState{
 		issueNumber: int(pr.GetNumber()),
 		labels:      labelNames(pr.Labels),
 
 		draft:      pr.GetDraft(),
+		closed:     pr.GetState() == "closed",
+		merged:     pr.GetMerged(),
 		title:      pr.GetTitle(),
+		body:       pr.GetBody(),
+		checklist:  parseChecklist(pr.GetBody()),
 		branchName: pr.GetHead().GetRef(),
+		baseBranch: pr.GetBase().GetRef(),
+
+		author:            pr.GetUser().GetLogin(),
+		authorIsBot:       github.IsBot(pr.GetUser()),
+		authorAssociation: pr.GetAuthorAssociation(),
+
+		additions:      pr.GetAdditions(),
+		deletions:      pr.GetDeletions(),
+		changedFiles:   pr.GetChangedFiles(),
+		commits:        pr.GetCommits(),
+		comments:       pr.GetComments(),
+		reviewComments: pr.GetReviewComments(),
+		headSHA:        pr.GetHead().GetSHA(),
+	}
+
+	for _, user := range pr.RequestedReviewers {
+		state.requestedReviewers = append(state.requestedReviewers, user.GetLogin())
+	}
+	for _, team := range pr.RequestedTeams {
+		state.requestedTeams = append(state.requestedTeams, team.GetSlug())
 	}
 
 	reviews, err := client.PullRequestReviews(int(pr.GetNumber()))
@@ -172,18 +804,139 @@ func prStateFromEvent(client reviewsLister, eventName string, payload []byte) (p
 	}
 	log.Println("Retrieved review states:", reviews)
 
-	for _, review := range reviews {
-		if review == github.Approved {
-			state.approved = true
+	reviews, err = filterReviews(client, reviews, config.Reviews)
+	if err != nil {
+		return prState{}, fmt.Errorf("couldn't filter pull request reviews: %w", err)
+	}
+	state.allApprovals = countReviews(reviews, github.Approved, "")
+	state.currentApprovals = countReviews(reviews, github.Approved, state.headSHA)
+	state.approvals = state.allApprovals
+	if config.Reviews.IgnoreStaleApprovals {
+		state.approvals = state.currentApprovals
+	}
+	state.changesRequests = countReviews(reviews, github.ChangesRequested, "")
+	state.approved = state.approvals > 0
+	state.changesRequested = state.changesRequests > 0
+
+	approverCommit := ""
+	if config.Reviews.IgnoreStaleApprovals {
+		approverCommit = state.headSHA
+	}
+	state.approvers = approvers(reviews, approverCommit)
+
+	if config.usesFiles() {
+		files, err := client.ListFiles(int(pr.GetNumber()))
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't list pull request files: %w", err)
+		}
+		for _, file := range files {
+			state.files = append(state.files, changedFile{
+				path:      file.Filename,
+				status:    file.Status,
+				additions: file.Additions,
+				deletions: file.Deletions,
+			})
+		}
+	}
+
+	// Look up the author's membership of the teams the rules reference.
+	state.authorTeams, err = teamMemberships(client, state.author, config.authorTeams())
+	if err != nil {
+		return prState{}, fmt.Errorf("couldn't look up author teams: %w", err)
+	}
+
+	if config.Owners != nil || config.usesCodeownerApproved() {
+		rules, err := loadCodeowners(client)
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't load CODEOWNERS: %w", err)
+		}
+		state.owners = rules.owners(state.files)
+		if config.usesCodeownerApproved() {
+			state.codeownerApproved, err = codeownersApproved(client, rules, state.files, state.approvers)
+			if err != nil {
+				return prState{}, fmt.Errorf("couldn't check code owner approvals: %w", err)
+			}
 		}
-		if review == github.ChangesRequested {
-			state.changesRequested = true
+	}
+
+	state.teamApprovers, err = teamApprovers(client, config.requirementTeams(), state.approvers)
+	if err != nil {
+		return prState{}, fmt.Errorf("couldn't look up approver teams: %w", err)
+	}
+
+	if config.usesChecks() {
+		state.checks, err = loadChecks(client, state.headSHA, config.ignoredChecks())
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't load checks: %w", err)
 		}
 	}
 
+	if config.usesUnresolvedThreads() {
+		state.unresolvedThreads, err = client.UnresolvedReviewThreads(int(pr.GetNumber()))
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't count unresolved review threads: %w", err)
+		}
+	}
+
+	// Mergeability is unknown until Github has computed it, so it's only
+	// considered conflicting once Github says so.
+	state.mergeable = true
+	if config.usesMergeable() {
+		mergeable := pr.Mergeable
+		if mergeable == nil {
+			mergeable, err = client.Mergeable(int(pr.GetNumber()))
+			if err != nil {
+				return prState{}, fmt.Errorf("couldn't check mergeability: %w", err)
+			}
+		}
+		state.mergeable = mergeable == nil || *mergeable
+	}
+
+	if config.usesBehindBase() {
+		behindBy, err := client.BehindBy(state.baseBranch, state.headSHA)
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't compare with base branch: %w", err)
+		}
+		state.behindBase = behindBy > 0
+	}
+
+	if config.usesLinkedIssues() {
+		refs := linkedIssueReferences(state.body, state.branchName, pr.GetBase().GetRepo().GetFullName())
+		state.linkedIssues, err = loadLinkedIssues(client, refs)
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't load linked issues: %w", err)
+		}
+	}
+
+	if config.WaitingOn != nil || config.usesAllReviewersResponded() {
+		events, err := client.PullRequestTimeline(int(pr.GetNumber()))
+		if err != nil {
+			return prState{}, fmt.Errorf("couldn't list pull request timeline: %w", err)
+		}
+		state.waitingOn = waitingOn(events, state.author, state.draft)
+		state.reviewed = anyReviews(events)
+	}
+
 	return state, nil
 }
 
+type teamMemberChecker interface {
+	IsTeamMember(team, user string) (bool, error)
+}
+
+// teamMemberships maps each of the teams to whether the user is a member.
+func teamMemberships(client teamMemberChecker, user string, teams []string) (map[string]bool, error) {
+	memberships := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		member, err := client.IsTeamMember(team, user)
+		if err != nil {
+			return nil, err
+		}
+		memberships[team] = member
+	}
+	return memberships, nil
+}
+
 func labelNames(labels []*gh.Label) []string {
 	strings := make([]string, 0, len(labels))
 	for _, label := range labels {
@@ -212,6 +965,24 @@ func addLabel(labels []string, addition string) []string {
 	return append(labels, addition)
 }
 
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
 func stringSlicesEqual(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -1,15 +1,49 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"strconv"
 	"testing"
 
 	gh "github.com/google/go-github/v29/github"
+	"gopkg.in/yaml.v3"
+
+	"github.com/MTIConnect/labeler-action/github"
 )
 
+func TestLabelerConfigUnmarshal(t *testing.T) {
+	data := []byte(`
+WIP:
+  draft: true
+
+size:
+  exclude: ["**/*.pb.go"]
+  buckets:
+    size/XS: 0
+    size/S: 10
+`)
+
+	var config labelerConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if len(config.Labels) != 1 || config.Labels["WIP"].Draft == nil {
+		t.Fatalf("expected only the WIP label rule, got: %v", config.Labels)
+	}
+	if config.Size == nil {
+		t.Fatalf("expected size rule, got: nil")
+	}
+	if config.Size.Buckets["size/S"] != 10 {
+		t.Fatalf("expected size/S minimum: 10, got: %d", config.Size.Buckets["size/S"])
+	}
+}
+
 func TestLabelsForPRState(t *testing.T) {
 	falseCheck := false
 	trueCheck := true
-	config := labelerConfig{
+	config := labelerConfig{Labels: map[string]labelConditions{
 		"WIP": {
 			Draft: &trueCheck,
 		},
@@ -36,7 +70,7 @@ func TestLabelsForPRState(t *testing.T) {
 			ChangesRequested: &falseCheck,
 			Approved:         &trueCheck,
 		},
-	}
+	}}
 
 	tests := []struct {
 		name     string
@@ -102,6 +136,695 @@ func TestLabelsForPRState(t *testing.T) {
 	}
 }
 
+func TestFilesConditionMatches(t *testing.T) {
+	files := []changedFile{
+		{path: "docs/guide.md", status: "modified"},
+		{path: "README.md", status: "modified"},
+		{path: "db/migrations/0002_widgets.sql", status: "added"},
+		{path: "db/migrations/0001_init.sql", status: "removed"},
+	}
+
+	tests := []struct {
+		name      string
+		condition filesCondition
+		files     []changedFile
+		expected  bool
+	}{
+		{
+			name:      "No Files",
+			condition: filesCondition{All: []string{"**/*.md"}},
+			files:     nil,
+			expected:  false,
+		},
+		{
+			name:      "Any Matches",
+			condition: filesCondition{Any: []string{"db/migrations/**"}},
+			files:     files,
+			expected:  true,
+		},
+		{
+			name:      "Any No Matches",
+			condition: filesCondition{Any: []string{"src/**"}},
+			files:     files,
+			expected:  false,
+		},
+		{
+			name:      "All Matches",
+			condition: filesCondition{All: []string{"**/*.md"}},
+			files:     files[:2],
+			expected:  true,
+		},
+		{
+			name:      "All Partial Match",
+			condition: filesCondition{All: []string{"**/*.md"}},
+			files:     files,
+			expected:  false,
+		},
+		{
+			name:      "All With Negation",
+			condition: filesCondition{All: []string{"**", "!README.md"}},
+			files:     files[:2],
+			expected:  false,
+		},
+		{
+			name: "Status Filter",
+			condition: filesCondition{
+				All:    []string{"db/migrations/*.sql"},
+				Status: []string{"added", "removed"},
+			},
+			files:    files,
+			expected: true,
+		},
+		{
+			name:      "Status Only",
+			condition: filesCondition{Status: []string{"renamed"}},
+			files:     files,
+			expected:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.condition.matches(tc.files)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAuthorConditionMatches(t *testing.T) {
+	trueCheck := true
+	falseCheck := false
+	dependabot := prState{
+		author:            "dependabot[bot]",
+		authorIsBot:       true,
+		authorAssociation: "NONE",
+	}
+	contributor := prState{
+		author:            "octocat",
+		authorAssociation: "FIRST_TIME_CONTRIBUTOR",
+	}
+
+	tests := []struct {
+		name      string
+		condition authorCondition
+		state     prState
+		expected  bool
+	}{
+		{
+			name:      "Login Regexp",
+			condition: authorCondition{Login: "^dependabot"},
+			state:     dependabot,
+			expected:  true,
+		},
+		{
+			name:      "Login Regexp No Match",
+			condition: authorCondition{Login: "^dependabot"},
+			state:     contributor,
+			expected:  false,
+		},
+		{
+			name:      "Logins Allowlist",
+			condition: authorCondition{Logins: []string{"hubot", "OctoCat"}},
+			state:     contributor,
+			expected:  true,
+		},
+		{
+			name:      "Is Bot",
+			condition: authorCondition{IsBot: &trueCheck},
+			state:     dependabot,
+			expected:  true,
+		},
+		{
+			name:      "Is Not Bot",
+			condition: authorCondition{IsBot: &falseCheck},
+			state:     dependabot,
+			expected:  false,
+		},
+		{
+			name: "External Contributor",
+			condition: authorCondition{
+				IsBot:       &falseCheck,
+				Association: []string{"first_time_contributor", "contributor"},
+			},
+			state:    contributor,
+			expected: true,
+		},
+		{
+			name:      "Member",
+			condition: authorCondition{Association: []string{"MEMBER", "OWNER"}},
+			state:     contributor,
+			expected:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.condition.matches(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLabelsForPRStateAuthorTeam(t *testing.T) {
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Platform": {
+			AuthorTeam: []string{"platform", "sre"},
+		},
+		"Payments": {
+			AuthorTeam: []string{"payments"},
+		},
+	}}
+	assertStringSlicesEqualUnordered(t, []string{"platform", "sre", "payments"}, config.authorTeams())
+
+	state := prState{
+		labels:      []string{"Payments"},
+		authorTeams: map[string]bool{"platform": false, "sre": true, "payments": false},
+	}
+	actual, err := config.labelsForPRState(state)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	assertStringSlicesEqual(t, []string{"Platform"}, actual)
+}
+
+func TestLabelsForPRStateReviewRequests(t *testing.T) {
+	trueCheck := true
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Awaiting Review": {
+			ReviewRequested: &trueCheck,
+		},
+		"Platform Review": {
+			RequestedTeam: "Platform",
+		},
+		"Reviewed": {
+			AllReviewersResponded: &trueCheck,
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name:     "No Requests Or Reviews",
+			state:    prState{},
+			expected: []string{},
+		},
+		{
+			name:     "Team Requested",
+			state:    prState{requestedTeams: []string{"platform"}, reviewed: true},
+			expected: []string{"Awaiting Review", "Platform Review"},
+		},
+		{
+			name:     "User Requested",
+			state:    prState{labels: []string{"Reviewed"}, requestedReviewers: []string{"octocat"}},
+			expected: []string{"Awaiting Review"},
+		},
+		{
+			name:     "All Responded",
+			state:    prState{labels: []string{"Awaiting Review"}, reviewed: true},
+			expected: []string{"Reviewed"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestPRStatesFromEventReviewersResponded(t *testing.T) {
+	trueCheck := true
+	payload := []byte(`{"pull_request": {"number": 7, "user": {"login": "author"}}}`)
+	config := labelerConfig{
+		Reviews: reviewSettings{Ignore: []string{"hubot"}},
+		Labels: map[string]labelConditions{
+			"Reviewed": {
+				AllReviewersResponded: &trueCheck,
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		client   fakePRClient
+		expected []string
+	}{
+		{
+			name: "Comment Review",
+			client: fakePRClient{
+				timeline: []github.TimelineEvent{
+					{Event: "review_requested", Actor: "author"},
+					{Event: "reviewed", Actor: "octocat", ReviewState: github.Commented},
+				},
+			},
+			expected: []string{"Reviewed"},
+		},
+		{
+			name: "Ignored Reviewer",
+			client: fakePRClient{
+				reviews: []github.Review{{User: "hubot", State: github.Approved}},
+				timeline: []github.TimelineEvent{
+					{Event: "reviewed", Actor: "hubot", ReviewState: github.Approved},
+				},
+			},
+			expected: []string{"Reviewed"},
+		},
+		{
+			name: "No Reviews",
+			client: fakePRClient{
+				timeline: []github.TimelineEvent{
+					{Event: "commented", Actor: "octocat"},
+				},
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			states, err := prStatesFromEvent(tc.client, config, "pull_request", payload)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			actual, err := config.labelsForPRState(states[0])
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
+
+type fakePRClient struct {
+	reviews     []github.Review
+	permissions map[string]string
+	files       []github.PullRequestFile
+	prs         map[int]*gh.PullRequest
+	heads       map[string][]int
+	statuses    []github.CheckResult
+	runs        []github.CheckResult
+	teams       map[string][]string
+	content     map[string][]byte
+	timeline    []github.TimelineEvent
+	unresolved  int
+	mergeable   *bool
+	behindBy    map[string]int
+	issues      map[string]github.Issue
+}
+
+func (c fakePRClient) Issue(repo string, number int) (github.Issue, error) {
+	key := repo + "#" + strconv.Itoa(number)
+	issue, ok := c.issues[key]
+	if !ok {
+		return github.Issue{}, fmt.Errorf("%w: %v", github.ErrIssueNotFound, key)
+	}
+	return issue, nil
+}
+
+func (c fakePRClient) Mergeable(int) (*bool, error) {
+	return c.mergeable, nil
+}
+
+func (c fakePRClient) BehindBy(base, head string) (int, error) {
+	return c.behindBy[base+"..."+head], nil
+}
+
+func (c fakePRClient) UnresolvedReviewThreads(int) (int, error) {
+	return c.unresolved, nil
+}
+
+func (c fakePRClient) PullRequestTimeline(int) ([]github.TimelineEvent, error) {
+	return c.timeline, nil
+}
+
+func (c fakePRClient) PullRequestReviews(int) ([]github.Review, error) {
+	return c.reviews, nil
+}
+
+func (c fakePRClient) PermissionLevel(user string) (string, error) {
+	permission, ok := c.permissions[user]
+	if !ok {
+		return "none", nil
+	}
+	return permission, nil
+}
+
+func (c fakePRClient) ListFiles(int) ([]github.PullRequestFile, error) {
+	return c.files, nil
+}
+
+func (c fakePRClient) PullRequest(number int) (*gh.PullRequest, error) {
+	pr, ok := c.prs[number]
+	if !ok {
+		return nil, fmt.Errorf("pull request #%d not found", number)
+	}
+	return pr, nil
+}
+
+func (c fakePRClient) PullRequestsForHead(sha string) ([]int, error) {
+	return c.heads[sha], nil
+}
+
+func (c fakePRClient) CombinedStatus(string) ([]github.CheckResult, error) {
+	return c.statuses, nil
+}
+
+func (c fakePRClient) ListCheckRuns(string) ([]github.CheckResult, error) {
+	return c.runs, nil
+}
+
+func (c fakePRClient) IsTeamMember(team, user string) (bool, error) {
+	return contains(c.teams[team], user), nil
+}
+
+func (c fakePRClient) DownloadFileFromDefaultBranch(path string) ([]byte, error) {
+	data, ok := c.content[path]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", github.ErrFileNotFound, path)
+	}
+	return data, nil
+}
+
+func TestPRStatesFromEvent(t *testing.T) {
+	payload := []byte(`{
+		"action": "opened",
+		"number": 7,
+		"pull_request": {
+			"number": 7,
+			"title": "Bump yaml.v3",
+			"draft": false,
+			"additions": 12,
+			"commits": 1,
+			"author_association": "NONE",
+			"user": {"login": "dependabot[bot]", "type": "Bot"},
+			"head": {"ref": "dependabot/go_modules/yaml.v3"},
+			"base": {"ref": "master"},
+			"labels": [{"name": "Dependencies"}],
+			"requested_reviewers": [{"login": "hubot"}],
+			"requested_teams": [{"slug": "platform"}]
+		}
+	}`)
+	client := fakePRClient{
+		reviews: []github.Review{{User: "octocat", State: github.Approved}},
+		files: []github.PullRequestFile{
+			{Filename: "go.mod", Status: "modified", Additions: 1, Deletions: 1},
+		},
+	}
+
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Go Modules": {Files: &filesCondition{Any: []string{"go.mod"}}},
+	}}
+
+	states, err := prStatesFromEvent(client, config, "pull_request", payload)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("expected one state, got: %d", len(states))
+	}
+	state := states[0]
+
+	if state.issueNumber != 7 {
+		t.Errorf("expected issue number: 7, got: %d", state.issueNumber)
+	}
+	assertStringSlicesEqual(t, []string{"Dependencies"}, state.labels)
+	if state.title != "Bump yaml.v3" || state.branchName != "dependabot/go_modules/yaml.v3" || state.baseBranch != "master" {
+		t.Errorf("unexpected title and branches: %q, %q, %q", state.title, state.branchName, state.baseBranch)
+	}
+	if state.author != "dependabot[bot]" || !state.authorIsBot || state.authorAssociation != "NONE" {
+		t.Errorf("unexpected author: %q, bot: %t, association: %q", state.author, state.authorIsBot, state.authorAssociation)
+	}
+	if state.additions != 12 || state.commits != 1 {
+		t.Errorf("unexpected counts: additions %d, commits %d", state.additions, state.commits)
+	}
+	if !state.approved || state.changesRequested {
+		t.Errorf("unexpected reviews: approved %t, changes requested %t", state.approved, state.changesRequested)
+	}
+	if len(state.files) != 1 || state.files[0].path != "go.mod" || state.files[0].additions != 1 {
+		t.Errorf("unexpected files: %v", state.files)
+	}
+	assertStringSlicesEqual(t, []string{"hubot"}, state.requestedReviewers)
+	assertStringSlicesEqual(t, []string{"platform"}, state.requestedTeams)
+}
+
+func TestPRStatesFromEventWithoutFiles(t *testing.T) {
+	payload := []byte(`{"pull_request": {"number": 7, "title": "Bump yaml.v3"}}`)
+	client := fakePRClient{
+		files: []github.PullRequestFile{{Filename: "go.mod", Status: "modified"}},
+	}
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Dependencies": {Title: "^Bump "},
+	}}
+
+	states, err := prStatesFromEvent(client, config, "pull_request", payload)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(states[0].files) != 0 {
+		t.Errorf("expected files not to be listed, got: %v", states[0].files)
+	}
+}
+
+func TestPRStatesFromReviewEventCounts(t *testing.T) {
+	payload := []byte(`{
+		"action": "submitted",
+		"review": {"state": "approved"},
+		"pull_request": {
+			"number": 7,
+			"title": "Bump yaml.v3",
+			"head": {"ref": "dependabot/go_modules/yaml.v3"},
+			"base": {"ref": "master"}
+		}
+	}`)
+	additions, commits := 640, 3
+	client := fakePRClient{
+		prs: map[int]*gh.PullRequest{
+			7: {
+				Number:    gh.Int(7),
+				Title:     gh.String("Bump yaml.v3"),
+				Additions: &additions,
+				Commits:   &commits,
+			},
+		},
+	}
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Huge PR": {Additions: &comparison{operator: ">", value: 500}},
+	}}
+
+	states, err := prStatesFromEvent(client, config, "pull_request_review", payload)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("expected one state, got: %d", len(states))
+	}
+	if states[0].additions != 640 || states[0].commits != 3 {
+		t.Errorf("unexpected counts: additions %d, commits %d", states[0].additions, states[0].commits)
+	}
+
+	// The pull request isn't fetched when no rule tests a count.
+	states, err = prStatesFromEvent(client, labelerConfig{}, "pull_request_review", payload)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if states[0].additions != 0 {
+		t.Errorf("expected payload counts, got additions: %d", states[0].additions)
+	}
+}
+
+func TestPRStatesFromCheckEvents(t *testing.T) {
+	client := fakePRClient{
+		prs: map[int]*gh.PullRequest{
+			3: {Number: intToPtr(3), Head: &gh.PullRequestBranch{SHA: stringToPtr("abc123")}},
+			4: {Number: intToPtr(4), Head: &gh.PullRequestBranch{SHA: stringToPtr("abc123")}},
+		},
+		heads:    map[string][]int{"abc123": {3, 4}},
+		statuses: []github.CheckResult{{Name: "ci/jenkins", Conclusion: "failure"}, {Name: "build", Conclusion: "pending"}},
+		runs:     []github.CheckResult{{Name: "build", Conclusion: "success"}},
+	}
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"CI Failing": {ChecksAny: "failure"},
+	}}
+
+	tests := []struct {
+		name      string
+		eventName string
+		payload   string
+		expected  []int
+	}{
+		{
+			name:      "Check Run",
+			eventName: "check_run",
+			payload:   `{"check_run": {"head_sha": "abc123", "pull_requests": [{"number": 3}]}}`,
+			expected:  []int{3},
+		},
+		{
+			name:      "Check Suite",
+			eventName: "check_suite",
+			payload:   `{"check_suite": {"head_sha": "abc123", "pull_requests": [{"number": 4}, {"number": 3}]}}`,
+			expected:  []int{4, 3},
+		},
+		{
+			name:      "Status",
+			eventName: "status",
+			payload:   `{"sha": "abc123", "state": "failure", "context": "ci/jenkins"}`,
+			expected:  []int{3, 4},
+		},
+		{
+			name:      "Status Without Pull Requests",
+			eventName: "status",
+			payload:   `{"sha": "def456", "state": "success", "context": "ci/jenkins"}`,
+			expected:  []int{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			states, err := prStatesFromEvent(client, config, tc.eventName, []byte(tc.payload))
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if len(states) != len(tc.expected) {
+				t.Fatalf("expected %d states, got: %d", len(tc.expected), len(states))
+			}
+			for i, state := range states {
+				if state.issueNumber != tc.expected[i] {
+					t.Errorf("expected pull request #%d, got: #%d", tc.expected[i], state.issueNumber)
+				}
+				expectedChecks := map[string]string{"ci/jenkins": "failure", "build": "success"}
+				if len(state.checks) != len(expectedChecks) {
+					t.Errorf("expected checks: %v, got: %v", expectedChecks, state.checks)
+				}
+				for name, conclusion := range expectedChecks {
+					if state.checks[name] != conclusion {
+						t.Errorf("expected checks: %v, got: %v", expectedChecks, state.checks)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestChecksMatch(t *testing.T) {
+	checks := map[string]string{
+		"build":      "success",
+		"lint":       "success",
+		"ci/jenkins": "pending",
+	}
+
+	tests := []struct {
+		name       string
+		conditions labelConditions
+		checks     map[string]string
+		expected   bool
+	}{
+		{
+			name:       "Named Check",
+			conditions: labelConditions{Checks: map[string]string{"build": "SUCCESS"}},
+			checks:     checks,
+			expected:   true,
+		},
+		{
+			name:       "Named Check Differs",
+			conditions: labelConditions{Checks: map[string]string{"build": "success", "ci/jenkins": "success"}},
+			checks:     checks,
+			expected:   false,
+		},
+		{
+			name:       "Named Check Missing",
+			conditions: labelConditions{Checks: map[string]string{"deploy": "success"}},
+			checks:     checks,
+			expected:   false,
+		},
+		{
+			name:       "All Checks",
+			conditions: labelConditions{ChecksAll: "success"},
+			checks:     checks,
+			expected:   false,
+		},
+		{
+			name:       "All Checks Without Checks",
+			conditions: labelConditions{ChecksAll: "success"},
+			checks:     nil,
+			expected:   false,
+		},
+		{
+			name:       "Any Check",
+			conditions: labelConditions{ChecksAny: "pending"},
+			checks:     checks,
+			expected:   true,
+		},
+		{
+			name:       "Any Check None",
+			conditions: labelConditions{ChecksAny: "failure"},
+			checks:     checks,
+			expected:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.conditions.checksMatch(tc.checks)
+			if actual != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLoadChecks(t *testing.T) {
+	job := os.Getenv("GITHUB_JOB")
+	defer os.Setenv("GITHUB_JOB", job)
+	os.Setenv("GITHUB_JOB", "labeler")
+
+	client := fakePRClient{
+		statuses: []github.CheckResult{
+			{Name: "ci/jenkins", Conclusion: "success"},
+			{Name: "build", Conclusion: "failure"},
+		},
+		runs: []github.CheckResult{
+			{Name: "build", Conclusion: "success"},
+			{Name: "labeler", Conclusion: "pending"},
+			{Name: "Label Pull Request", Conclusion: "pending"},
+		},
+	}
+	config := labelerConfig{IgnoreChecks: []string{"Label *"}}
+
+	checks, err := loadChecks(client, "abc123", config.ignoredChecks())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	expected := map[string]string{"ci/jenkins": "success", "build": "success"}
+	if len(checks) != len(expected) {
+		t.Fatalf("expected: %v, got: %v", expected, checks)
+	}
+	for name, conclusion := range expected {
+		if checks[name] != conclusion {
+			t.Errorf("expected %s: %q, got: %q", name, conclusion, checks[name])
+		}
+	}
+	if !(labelConditions{ChecksAll: "success"}).checksMatch(checks) {
+		t.Errorf("expected checks_all: success to match without the running job")
+	}
+}
+
 func TestLabelNames(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -267,3 +990,7 @@ func assertStringSlicesEqual(t *testing.T, a, b []string) {
 func stringToPtr(s string) *string {
 	return &s
 }
+
+func intToPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,80 @@
+package main
+
+import "strings"
+
+// managed reports whether the label is under one of the managed prefixes.
+func (c labelerConfig) managed(label string) bool {
+	for _, prefix := range c.ManagedPrefixes {
+		if strings.HasPrefix(label, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// managedLabelsForPRState limits the rules to the labels under the managed
+// prefixes. Managed labels are only kept when this run applies them, and
+// other labels are left as they are on the pull request, even when a rule
+// has their name.
+func (c labelerConfig) managedLabelsForPRState(state prState) ([]string, error) {
+	var unmanaged []string
+	for _, label := range state.labels {
+		if !c.managed(label) {
+			unmanaged = append(unmanaged, label)
+		}
+	}
+
+	// Rules start without the managed labels, so every managed label left
+	// afterwards was applied by this run.
+	computed, err := c.applyRules(state, unmanaged)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the order of the existing labels, so unchanged labels compare equal.
+	labels := make([]string, 0, len(computed))
+	for _, label := range state.labels {
+		if !c.managed(label) || contains(computed, label) {
+			labels = append(labels, label)
+		}
+	}
+	for _, label := range computed {
+		if c.managed(label) {
+			labels = addLabel(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+type labelWriter interface {
+	ReplaceLabelsForIssue(int, []string) error
+	EditLabelsForIssue(repo string, number int, add, remove []string) error
+}
+
+// writeLabels updates the labels of the pull request to the calculated
+// labels. With managed prefixes only managed labels are added and removed,
+// so labels added by others since the event was sent are kept.
+func (c labelerConfig) writeLabels(client labelWriter, state prState, labels []string) error {
+	if len(c.ManagedPrefixes) == 0 {
+		if stringSlicesEqual(state.labels, labels) {
+			return nil
+		}
+		return client.ReplaceLabelsForIssue(state.issueNumber, labels)
+	}
+
+	var add, remove []string
+	for _, label := range labels {
+		if c.managed(label) && !contains(state.labels, label) {
+			add = append(add, label)
+		}
+	}
+	for _, label := range state.labels {
+		if c.managed(label) && !contains(labels, label) {
+			remove = append(remove, label)
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+	return client.EditLabelsForIssue("", state.issueNumber, add, remove)
+}
@@ -0,0 +1,153 @@
+package main
+
+import "testing"
+
+func TestLabelsForPRStateManagedPrefixes(t *testing.T) {
+	trueCheck := true
+	config := labelerConfig{
+		ManagedPrefixes: []string{"size/", "status/"},
+		Size:            &sizeRule{Buckets: map[string]int{"size/S": 0, "size/L": 100}},
+		Labels: map[string]labelConditions{
+			"status/approved": {
+				Approved: &trueCheck,
+			},
+			"status/ready": {
+				RequiresLabels: []string{"status/approved"},
+			},
+			"Bug": {
+				BranchName: "^bug/",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name: "Stale Managed Labels Removed",
+			state: prState{
+				labels: []string{"size/L", "status/in-review", "status/approved", "team/platform"},
+			},
+			expected: []string{"team/platform", "size/S"},
+		},
+		{
+			name: "Managed Labels Kept When Computed",
+			state: prState{
+				labels:   []string{"needs-triage", "status/approved", "size/S"},
+				approved: true,
+				files:    []changedFile{{path: "main.go", additions: 150}},
+			},
+			expected: []string{"needs-triage", "status/approved", "size/L", "status/ready"},
+		},
+		{
+			name: "Unmanaged Rule Labels Untouched",
+			state: prState{
+				labels:     []string{"Bug"},
+				branchName: "feature/login",
+			},
+			expected: []string{"Bug", "size/S"},
+		},
+		{
+			name: "Unmanaged Rule Labels Not Added",
+			state: prState{
+				branchName: "bug/login",
+			},
+			expected: []string{"size/S"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqual(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLabelsForPRStateManagedPrefixesUnchanged(t *testing.T) {
+	config := labelerConfig{
+		ManagedPrefixes: []string{"size/"},
+		Size:            &sizeRule{Buckets: map[string]int{"size/S": 0}},
+	}
+	state := prState{labels: []string{"size/S", "Bug"}}
+
+	actual, err := config.labelsForPRState(state)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !stringSlicesEqual(state.labels, actual) {
+		t.Fatalf("expected unchanged labels to keep their order, got: %v", actual)
+	}
+}
+
+// fakeLabelWriter holds the live labels of a pull request, which may differ
+// from the labels of the event payload.
+type fakeLabelWriter struct {
+	labels   []string
+	replaced bool
+}
+
+func (w *fakeLabelWriter) ReplaceLabelsForIssue(number int, labels []string) error {
+	w.labels = labels
+	w.replaced = true
+	return nil
+}
+
+func (w *fakeLabelWriter) EditLabelsForIssue(repo string, number int, add, remove []string) error {
+	for _, label := range add {
+		w.labels = addLabel(w.labels, label)
+	}
+	for _, label := range remove {
+		w.labels = removeLabel(w.labels, label)
+	}
+	return nil
+}
+
+func TestWriteLabelsManagedPrefixes(t *testing.T) {
+	config := labelerConfig{
+		ManagedPrefixes: []string{"size/"},
+		Size:            &sizeRule{Buckets: map[string]int{"size/S": 0, "size/L": 100}},
+	}
+	// The payload was sent before another bot labeled the pull request.
+	state := prState{
+		issueNumber: 1,
+		labels:      []string{"Bug", "size/S"},
+		files:       []changedFile{{path: "main.go", additions: 150}},
+	}
+	client := &fakeLabelWriter{labels: []string{"Bug", "size/S", "needs-triage"}}
+
+	labels, err := config.labelsForPRState(state)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := config.writeLabels(client, state, labels); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if client.replaced {
+		t.Errorf("expected managed labels to be edited, not replaced")
+	}
+	assertStringSlicesEqualUnordered(t, []string{"Bug", "needs-triage", "size/L"}, client.labels)
+}
+
+func TestWriteLabelsUnmanaged(t *testing.T) {
+	config := labelerConfig{}
+	client := &fakeLabelWriter{labels: []string{"Bug"}}
+
+	if err := config.writeLabels(client, prState{labels: []string{"Bug"}}, []string{"Bug"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if client.replaced {
+		t.Errorf("expected unchanged labels not to be written")
+	}
+
+	if err := config.writeLabels(client, prState{labels: []string{"Bug"}}, []string{"WIP"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	assertStringSlicesEqual(t, []string{"WIP"}, client.labels)
+}
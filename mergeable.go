@@ -0,0 +1,20 @@
+package main
+
+type mergeabilityChecker interface {
+	Mergeable(int) (*bool, error)
+	BehindBy(base, head string) (int, error)
+}
+
+// usesMergeable reports whether any rule has a mergeable condition.
+func (c labelerConfig) usesMergeable() bool {
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return conditions.Mergeable != nil || conditions.If.uses("mergeable")
+	})
+}
+
+// usesBehindBase reports whether any rule has a behind_base condition.
+func (c labelerConfig) usesBehindBase() bool {
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return conditions.BehindBase != nil || conditions.If.uses("behind_base")
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import "testing"
+
+func TestPRStatesFromEventMergeability(t *testing.T) {
+	trueCheck := true
+	falseCheck := false
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Conflicts": {
+			Mergeable: &falseCheck,
+		},
+		"Needs Rebase": {
+			BehindBase: &trueCheck,
+		},
+	}}
+
+	tests := []struct {
+		name      string
+		payload   string
+		client    fakePRClient
+		mergeable bool
+		behind    bool
+		expected  []string
+	}{
+		{
+			name:      "Conflicts In Payload",
+			payload:   `{"pull_request": {"number": 1, "mergeable": false, "head": {"sha": "abc123"}, "base": {"ref": "master"}}}`,
+			client:    fakePRClient{mergeable: &trueCheck},
+			mergeable: false,
+			expected:  []string{"Conflicts"},
+		},
+		{
+			name:      "Conflicts Fetched",
+			payload:   `{"pull_request": {"number": 1, "mergeable": null, "head": {"sha": "abc123"}, "base": {"ref": "master"}}}`,
+			client:    fakePRClient{mergeable: &falseCheck},
+			mergeable: false,
+			expected:  []string{"Conflicts"},
+		},
+		{
+			name:      "Unknown",
+			payload:   `{"pull_request": {"number": 1, "head": {"sha": "abc123"}, "base": {"ref": "master"}}}`,
+			client:    fakePRClient{},
+			mergeable: true,
+			expected:  []string{},
+		},
+		{
+			name:      "Behind Base",
+			payload:   `{"pull_request": {"number": 1, "mergeable": true, "head": {"sha": "abc123"}, "base": {"ref": "master"}}}`,
+			client:    fakePRClient{behindBy: map[string]int{"master...abc123": 4}},
+			mergeable: true,
+			behind:    true,
+			expected:  []string{"Needs Rebase"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			states, err := prStatesFromEvent(tc.client, config, "pull_request", []byte(tc.payload))
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			state := states[0]
+			if state.mergeable != tc.mergeable || state.behindBase != tc.behind {
+				t.Errorf("expected mergeable: %t, behind: %t, got: %t, %t", tc.mergeable, tc.behind, state.mergeable, state.behindBase)
+			}
+
+			actual, err := config.labelsForPRState(state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
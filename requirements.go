@@ -0,0 +1,104 @@
+package main
+
+import "fmt"
+
+// approvalRequirement requires approvals from a team or set of users when the
+// pull request changes files matching Paths. Its label is applied until the
+// requirement is met.
+type approvalRequirement struct {
+	Paths []string
+	Team  string
+	Users []string
+	// Approvals is the number of approvals needed, at least one.
+	Approvals int
+	// Label defaults to "needs-approval/<team>".
+	Label string
+}
+
+func (r approvalRequirement) label() (string, error) {
+	switch {
+	case r.Label != "":
+		return r.Label, nil
+	case r.Team != "":
+		return "needs-approval/" + r.Team, nil
+	default:
+		return "", fmt.Errorf("approval requirement for %v needs a team or label", r.Paths)
+	}
+}
+
+// met reports whether the requirement is satisfied, either because no
+// matching files changed or because enough of its approvers approved.
+func (r approvalRequirement) met(state prState) (bool, error) {
+	touched := false
+	for _, file := range state.files {
+		matched, err := matchGlobs(r.Paths, file.path)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			touched = true
+			break
+		}
+	}
+	if !touched {
+		return true, nil
+	}
+
+	var approvers []string
+	for _, approver := range state.approvers {
+		if containsFold(r.Users, approver) || contains(state.teamApprovers[r.Team], approver) {
+			approvers = append(approvers, approver)
+		}
+	}
+
+	needed := r.Approvals
+	if needed < 1 {
+		needed = 1
+	}
+	return len(approvers) >= needed, nil
+}
+
+// apply adds the requirement's label while it is unmet, and removes it once met.
+func (r approvalRequirement) apply(labels []string, state prState) ([]string, error) {
+	label, err := r.label()
+	if err != nil {
+		return nil, err
+	}
+	met, err := r.met(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match paths for %q: %w", label, err)
+	}
+
+	if met {
+		return removeLabel(labels, label), nil
+	}
+	return addLabel(labels, label), nil
+}
+
+// requirementTeams returns every team referenced by an approval requirement.
+func (c labelerConfig) requirementTeams() []string {
+	var teams []string
+	for _, requirement := range c.ApprovalRequirements {
+		if requirement.Team != "" && !contains(teams, requirement.Team) {
+			teams = append(teams, requirement.Team)
+		}
+	}
+	return teams
+}
+
+// teamApprovers maps each of the teams to the approvers that are its members.
+func teamApprovers(client teamMemberChecker, teams []string, approvers []string) (map[string][]string, error) {
+	members := make(map[string][]string, len(teams))
+	for _, team := range teams {
+		for _, approver := range approvers {
+			member, err := client.IsTeamMember(team, approver)
+			if err != nil {
+				return nil, err
+			}
+			if member {
+				members[team] = append(members[team], approver)
+			}
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestApprovalRequirementApply(t *testing.T) {
+	requirements := []approvalRequirement{
+		{
+			Paths: []string{"db/migrations/**"},
+			Team:  "dba",
+		},
+		{
+			Paths:     []string{"billing/**", "!billing/**/*.md"},
+			Users:     []string{"Alice", "bob"},
+			Approvals: 2,
+			Label:     "needs-approval/billing",
+		},
+	}
+	migration := changedFile{path: "db/migrations/0002_widgets.sql"}
+	billing := changedFile{path: "billing/invoice.go"}
+	billingDocs := changedFile{path: "billing/README.md"}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name: "Untouched",
+			state: prState{
+				labels: []string{"needs-approval/dba"},
+				files:  []changedFile{{path: "main.go"}, billingDocs},
+			},
+			expected: []string{},
+		},
+		{
+			name: "Unmet",
+			state: prState{
+				files:     []changedFile{migration, billing},
+				approvers: []string{"alice", "octocat"},
+			},
+			expected: []string{"needs-approval/dba", "needs-approval/billing"},
+		},
+		{
+			name: "Met",
+			state: prState{
+				labels:        []string{"needs-approval/dba", "needs-approval/billing"},
+				files:         []changedFile{migration, billing},
+				approvers:     []string{"alice", "bob", "dbadmin"},
+				teamApprovers: map[string][]string{"dba": {"dbadmin"}},
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := append([]string(nil), tc.state.labels...)
+			for _, requirement := range requirements {
+				var err error
+				actual, err = requirement.apply(actual, tc.state)
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+			}
+			assertStringSlicesEqual(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestApprovalRequirementWithoutLabel(t *testing.T) {
+	requirement := approvalRequirement{Paths: []string{"**"}, Users: []string{"alice"}}
+	_, err := requirement.apply(nil, prState{})
+	if err == nil {
+		t.Fatalf("expected error for requirement without team or label, got: nil")
+	}
+}
+
+func TestTeamApprovers(t *testing.T) {
+	client := fakePRClient{
+		teams: map[string][]string{
+			"dba":      {"dbadmin"},
+			"payments": {"alice", "bob"},
+		},
+	}
+
+	actual, err := teamApprovers(client, []string{"dba", "payments"}, []string{"bob", "dbadmin", "octocat"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	assertStringSlicesEqual(t, []string{"dbadmin"}, actual["dba"])
+	assertStringSlicesEqual(t, []string{"bob"}, actual["payments"])
+}
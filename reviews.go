@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MTIConnect/labeler-action/github"
+)
+
+// reviewSettings control which reviews count towards the review conditions.
+type reviewSettings struct {
+	// Ignore lists the logins of reviewers whose reviews never count.
+	Ignore []string
+	// IgnoreBots leaves out reviews by Github Apps and other bot accounts.
+	IgnoreBots bool `yaml:"ignore_bots"`
+	// MinPermission is the lowest repository permission a reviewer needs for
+	// their review to count, e.g. "write".
+	MinPermission string `yaml:"min_permission"`
+	// IgnoreStaleApprovals leaves out approvals of commits other than the
+	// head of the pull request.
+	IgnoreStaleApprovals bool `yaml:"ignore_stale_approvals"`
+}
+
+// permissionRanks orders the repository permission levels.
+var permissionRanks = map[string]int{
+	"none":     0,
+	"read":     1,
+	"triage":   2,
+	"write":    3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+// validate reports an unknown MinPermission before any review is filtered.
+func (s reviewSettings) validate() error {
+	if s.MinPermission == "" {
+		return nil
+	}
+	if _, ok := permissionRanks[strings.ToLower(s.MinPermission)]; !ok {
+		return fmt.Errorf("unknown permission level %q in reviews.min_permission", s.MinPermission)
+	}
+	return nil
+}
+
+type permissionGetter interface {
+	PermissionLevel(user string) (string, error)
+}
+
+// filterReviews returns the reviews that count under the settings.
+func filterReviews(client permissionGetter, reviews []github.Review, settings reviewSettings) ([]github.Review, error) {
+	minimum := 0
+	if settings.MinPermission != "" {
+		var ok bool
+		minimum, ok = permissionRanks[strings.ToLower(settings.MinPermission)]
+		if !ok {
+			return nil, fmt.Errorf("unknown permission level %q", settings.MinPermission)
+		}
+	}
+
+	var filtered []github.Review
+	for _, review := range reviews {
+		if containsFold(settings.Ignore, review.User) {
+			continue
+		}
+		if settings.IgnoreBots && review.IsBot {
+			continue
+		}
+		if minimum > 0 {
+			permission, err := client.PermissionLevel(review.User)
+			if err != nil {
+				return nil, err
+			}
+			if permissionRanks[permission] < minimum {
+				continue
+			}
+		}
+		filtered = append(filtered, review)
+	}
+	return filtered, nil
+}
+
+// countReviews returns the number of reviews in the state. When headSHA is
+// set, only reviews of that commit are counted.
+func countReviews(reviews []github.Review, state github.ReviewState, headSHA string) int {
+	count := 0
+	for _, review := range reviews {
+		if review.State == state && (headSHA == "" || review.CommitID == headSHA) {
+			count++
+		}
+	}
+	return count
+}
+
+// approvers returns the logins of the approving reviewers. When headSHA is
+// set, only approvals of that commit are included.
+func approvers(reviews []github.Review, headSHA string) []string {
+	var logins []string
+	for _, review := range reviews {
+		if review.State == github.Approved && (headSHA == "" || review.CommitID == headSHA) {
+			logins = append(logins, review.User)
+		}
+	}
+	return logins
+}
+
+// usesAllReviewersResponded reports whether any rule has an
+// all_requested_reviewers_responded condition.
+func (c labelerConfig) usesAllReviewersResponded() bool {
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return conditions.AllReviewersResponded != nil
+	})
+}
+
+// anyReviews reports whether the timeline has a submitted review. Unlike the
+// reviews that count towards the review conditions, comment reviews and
+// reviews the review settings leave out are included, as they all clear the
+// reviewer's request.
+func anyReviews(events []github.TimelineEvent) bool {
+	for _, event := range events {
+		if event.Event == "reviewed" {
+			return true
+		}
+	}
+	return false
+}
+
+type reviewThreadsCounter interface {
+	UnresolvedReviewThreads(int) (int, error)
+}
+
+// usesUnresolvedThreads reports whether any rule has an unresolved_threads condition.
+func (c labelerConfig) usesUnresolvedThreads() bool {
+	return c.anyConditions(func(conditions labelConditions) bool {
+		return conditions.UnresolvedThreads != nil || conditions.If.uses("unresolved_threads")
+	})
+}
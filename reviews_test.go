@@ -0,0 +1,272 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MTIConnect/labeler-action/github"
+)
+
+func TestFilterReviews(t *testing.T) {
+	client := fakePRClient{
+		permissions: map[string]string{
+			"maintainer": "admin",
+			"developer":  "write",
+			"triager":    "read",
+		},
+	}
+	reviews := []github.Review{
+		{User: "maintainer", State: github.Approved},
+		{User: "developer", State: github.ChangesRequested},
+		{User: "triager", State: github.Approved},
+		{User: "drive-by", State: github.Approved},
+		{User: "renovate[bot]", IsBot: true, State: github.Approved},
+	}
+
+	tests := []struct {
+		name     string
+		settings reviewSettings
+		expected []string
+	}{
+		{
+			name:     "No Filters",
+			settings: reviewSettings{},
+			expected: []string{"maintainer", "developer", "triager", "drive-by", "renovate[bot]"},
+		},
+		{
+			name:     "Ignore List",
+			settings: reviewSettings{Ignore: []string{"Drive-By", "triager"}},
+			expected: []string{"maintainer", "developer", "renovate[bot]"},
+		},
+		{
+			name:     "Ignore Bots",
+			settings: reviewSettings{IgnoreBots: true},
+			expected: []string{"maintainer", "developer", "triager", "drive-by"},
+		},
+		{
+			name:     "Write",
+			settings: reviewSettings{MinPermission: "write"},
+			expected: []string{"maintainer", "developer"},
+		},
+		{
+			name:     "Read",
+			settings: reviewSettings{MinPermission: "Read"},
+			expected: []string{"maintainer", "developer", "triager"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered, err := filterReviews(client, reviews, tc.settings)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			actual := []string{}
+			for _, review := range filtered {
+				actual = append(actual, review.User)
+			}
+			assertStringSlicesEqual(t, tc.expected, actual)
+		})
+	}
+
+	_, err := filterReviews(client, reviews, reviewSettings{MinPermission: "owner"})
+	if err == nil {
+		t.Fatalf("expected error for unknown permission, got: nil")
+	}
+}
+
+func TestReviewSettingsValidate(t *testing.T) {
+	for _, permission := range []string{"", "write", "Maintain"} {
+		if err := (reviewSettings{MinPermission: permission}).validate(); err != nil {
+			t.Errorf("unexpected err for %q: %v", permission, err)
+		}
+	}
+	if err := (reviewSettings{MinPermission: "owner"}).validate(); err == nil {
+		t.Errorf("expected error for unknown permission, got: nil")
+	}
+}
+
+func TestLabelsForPRStateApprovalCounts(t *testing.T) {
+	twoApprovals := comparison{operator: ">=", value: 2}
+	oneApproval := comparison{operator: "==", value: 1}
+	noChanges := comparison{operator: "==", value: 0}
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Needs Second Approval": {
+			Approvals:       &oneApproval,
+			ChangesRequests: &noChanges,
+		},
+		"Code Review Approved": {
+			Approvals:       &twoApprovals,
+			ChangesRequests: &noChanges,
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name:     "One Approval",
+			state:    prState{approvals: 1},
+			expected: []string{"Needs Second Approval"},
+		},
+		{
+			name:     "Two Approvals",
+			state:    prState{labels: []string{"Needs Second Approval"}, approvals: 2},
+			expected: []string{"Code Review Approved"},
+		},
+		{
+			name:     "Changes Requested",
+			state:    prState{labels: []string{"Code Review Approved"}, approvals: 2, changesRequests: 1},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLabelsForPRStateStaleApprovals(t *testing.T) {
+	trueCheck := true
+	falseCheck := false
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Code Review Approved": {
+			Approved: &trueCheck,
+		},
+		"Approved Latest": {
+			IgnoreStaleApprovals: &trueCheck,
+			Approved:             &trueCheck,
+		},
+		"Approved Any Commit": {
+			IgnoreStaleApprovals: &falseCheck,
+			Approved:             &trueCheck,
+		},
+		"Needs Re-review": {
+			Approved:               &trueCheck,
+			ApprovedOnLatestCommit: &falseCheck,
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name: "Approved Head",
+			state: prState{
+				approved:         true,
+				approvals:        1,
+				allApprovals:     1,
+				currentApprovals: 1,
+			},
+			expected: []string{"Code Review Approved", "Approved Latest", "Approved Any Commit"},
+		},
+		{
+			name: "Pushed After Approval",
+			state: prState{
+				approved:     true,
+				approvals:    1,
+				allApprovals: 1,
+			},
+			expected: []string{"Code Review Approved", "Approved Any Commit", "Needs Re-review"},
+		},
+		{
+			name: "Globally Ignored Stale Approval",
+			state: prState{
+				allApprovals: 1,
+			},
+			expected: []string{"Approved Any Commit"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestPRStatesFromEventStaleApprovals(t *testing.T) {
+	payload := []byte(`{"pull_request": {"number": 1, "head": {"sha": "def456"}}}`)
+	client := fakePRClient{
+		reviews: []github.Review{
+			{User: "octocat", State: github.Approved, CommitID: "abc123"},
+			{User: "hubot", State: github.Approved, CommitID: "def456"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		settings reviewSettings
+		expected int
+	}{
+		{name: "Counts Stale", settings: reviewSettings{}, expected: 2},
+		{name: "Ignores Stale", settings: reviewSettings{IgnoreStaleApprovals: true}, expected: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			states, err := prStatesFromEvent(client, labelerConfig{Reviews: tc.settings}, "pull_request", payload)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			state := states[0]
+			if state.approvals != tc.expected {
+				t.Errorf("expected approvals: %d, got: %d", tc.expected, state.approvals)
+			}
+			if state.allApprovals != 2 || state.currentApprovals != 1 {
+				t.Errorf("unexpected approvals: all %d, current %d", state.allApprovals, state.currentApprovals)
+			}
+		})
+	}
+}
+
+func TestLabelsForPRStateUnresolvedThreads(t *testing.T) {
+	payload := []byte(`{"pull_request": {"number": 1, "labels": [{"name": "Ready To Merge"}]}}`)
+	client := fakePRClient{
+		reviews:    []github.Review{{User: "octocat", State: github.Approved}},
+		unresolved: 2,
+	}
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"Ready To Merge": {
+			Approvals:         &comparison{operator: ">", value: 0},
+			UnresolvedThreads: &comparison{operator: "==", value: 0},
+		},
+		"Open Conversations": {
+			UnresolvedThreads: &comparison{operator: ">", value: 0},
+		},
+	}}
+
+	states, err := prStatesFromEvent(client, config, "pull_request", payload)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if states[0].unresolvedThreads != 2 {
+		t.Fatalf("expected unresolved threads: 2, got: %d", states[0].unresolvedThreads)
+	}
+	actual, err := config.labelsForPRState(states[0])
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	assertStringSlicesEqual(t, []string{"Open Conversations"}, actual)
+
+	states, err = prStatesFromEvent(client, labelerConfig{}, "pull_request", payload)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if states[0].unresolvedThreads != 0 {
+		t.Errorf("expected unresolved threads to be skipped, got: %d", states[0].unresolvedThreads)
+	}
+}
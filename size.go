@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/MTIConnect/labeler-action/github"
+)
+
+// sizeRule applies exactly one of its bucket labels, chosen by the number of
+// lines the pull request adds and deletes. Each bucket maps a label to the
+// minimum number of changed lines it applies to.
+type sizeRule struct {
+	Buckets map[string]int
+	Exclude []string
+}
+
+type sizeBucket struct {
+	label   string
+	minimum int
+}
+
+// apply removes every bucket label from labels, then adds the label of the
+// largest bucket the changed files reach.
+func (r sizeRule) apply(labels []string, files []changedFile) ([]string, error) {
+	buckets, err := r.sortedBuckets()
+	if err != nil {
+		return nil, err
+	}
+	lines, err := r.changedLines(files)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := ""
+	for _, bucket := range buckets {
+		labels = removeLabel(labels, bucket.label)
+		if lines >= bucket.minimum {
+			selected = bucket.label
+		}
+	}
+	if selected != "" {
+		labels = addLabel(labels, selected)
+	}
+	return labels, nil
+}
+
+func (r sizeRule) sortedBuckets() ([]sizeBucket, error) {
+	buckets := make([]sizeBucket, 0, len(r.Buckets))
+	for label, minimum := range r.Buckets {
+		buckets = append(buckets, sizeBucket{label: label, minimum: minimum})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].minimum < buckets[j].minimum
+	})
+
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].minimum == buckets[i-1].minimum {
+			return nil, fmt.Errorf("size buckets %q and %q share minimum %d",
+				buckets[i-1].label, buckets[i].label, buckets[i].minimum)
+		}
+	}
+	return buckets, nil
+}
+
+// changedLines sums the additions and deletions of the files that are
+// neither generated nor excluded by the rule.
+func (r sizeRule) changedLines(files []changedFile) (int, error) {
+	lines := 0
+	for _, file := range files {
+		if file.generated {
+			continue
+		}
+		if len(r.Exclude) > 0 {
+			excluded, err := matchGlobs(r.Exclude, file.path)
+			if err != nil {
+				return 0, err
+			}
+			if excluded {
+				continue
+			}
+		}
+		lines += file.additions + file.deletions
+	}
+	return lines, nil
+}
+
+type fileDownloader interface {
+	DownloadFileFromDefaultBranch(string) ([]byte, error)
+}
+
+// loadGitAttributes reads the root .gitattributes file of the repository.
+// Repositories without the file have no attributes.
+func loadGitAttributes(client fileDownloader) (gitAttributes, error) {
+	data, err := client.DownloadFileFromDefaultBranch(".gitattributes")
+	if errors.Is(err, github.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseGitAttributes(data), nil
+}
+
+// markGeneratedFiles flags the files linguist considers generated or vendored.
+func markGeneratedFiles(files []changedFile, attributes gitAttributes) []changedFile {
+	for i := range files {
+		files[i].generated = attributes.generated(files[i].path)
+	}
+	return files
+}
@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestSizeRuleApply(t *testing.T) {
+	rule := sizeRule{
+		Buckets: map[string]int{
+			"size/XS": 0,
+			"size/S":  10,
+			"size/M":  100,
+		},
+		Exclude: []string{"**/*.pb.go"},
+	}
+
+	tests := []struct {
+		name     string
+		labels   []string
+		files    []changedFile
+		expected []string
+	}{
+		{
+			name:     "No Files",
+			labels:   []string{"Bug"},
+			files:    nil,
+			expected: []string{"Bug", "size/XS"},
+		},
+		{
+			name:   "Replaces Stale Bucket",
+			labels: []string{"size/M", "Bug"},
+			files: []changedFile{
+				{path: "main.go", additions: 8, deletions: 4},
+			},
+			expected: []string{"Bug", "size/S"},
+		},
+		{
+			name:   "Largest Bucket",
+			labels: []string{"size/S"},
+			files: []changedFile{
+				{path: "main.go", additions: 60, deletions: 40},
+			},
+			expected: []string{"size/M"},
+		},
+		{
+			name: "Skips Generated And Excluded",
+			files: []changedFile{
+				{path: "main.go", additions: 3, deletions: 2},
+				{path: "api/widgets.pb.go", additions: 900},
+				{path: "vendor/lib.go", additions: 500, generated: true},
+			},
+			expected: []string{"size/XS"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := rule.apply(tc.labels, tc.files)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqual(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestSizeRuleApplyDuplicateMinimum(t *testing.T) {
+	rule := sizeRule{Buckets: map[string]int{"small": 0, "tiny": 0}}
+	_, err := rule.apply(nil, nil)
+	if err == nil {
+		t.Fatalf("expected error for buckets sharing a minimum, got: nil")
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateActionRegexp finds the actions within a templated label.
+var templateActionRegexp = regexp.MustCompile(`{{.*?}}`)
+
+// templateFieldRegexp matches an action that only renders a capture, e.g. "{{.team}}".
+var templateFieldRegexp = regexp.MustCompile(`^{{-?\s*\.(\w+)\s*-?}}$`)
+
+// matchCaptures reports whether pattern matches s, storing the values of its
+// named groups in captures.
+func matchCaptures(pattern, s string, captures map[string]string) (bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	match := re.FindStringSubmatch(s)
+	if match == nil {
+		return false, nil
+	}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			captures[name] = match[i]
+		}
+	}
+	return true, nil
+}
+
+// isLabelTemplate reports whether the label is rendered from regexp captures.
+func isLabelTemplate(label string) bool {
+	return strings.Contains(label, "{{")
+}
+
+// captureSubpatterns returns the subpattern of each named group of the title,
+// body, branch_name and base_branch regexes, including those of nested
+// blocks. Groups of the same name in several regexes are alternated.
+func (c labelConditions) captureSubpatterns() (map[string]string, error) {
+	alternatives := make(map[string][]string)
+	var err error
+	c.walk(func(conditions labelConditions) bool {
+		for _, pattern := range []string{conditions.Title, conditions.Body, conditions.BranchName, conditions.BaseBranch} {
+			if pattern == "" {
+				continue
+			}
+			var re *syntax.Regexp
+			re, err = syntax.Parse(pattern, syntax.Perl)
+			if err != nil {
+				return true
+			}
+			collectCaptures(re, alternatives)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subpatterns := make(map[string]string, len(alternatives))
+	for name, subs := range alternatives {
+		sort.Strings(subs)
+		subpatterns[name] = strings.Join(subs, "|")
+	}
+	return subpatterns, nil
+}
+
+// collectCaptures adds the subpattern of every named group within re to alternatives.
+func collectCaptures(re *syntax.Regexp, alternatives map[string][]string) {
+	if re.Op == syntax.OpCapture && re.Name != "" {
+		sub := re.Sub[0].String()
+		if !contains(alternatives[re.Name], sub) {
+			alternatives[re.Name] = append(alternatives[re.Name], sub)
+		}
+	}
+	for _, sub := range re.Sub {
+		collectCaptures(sub, alternatives)
+	}
+}
+
+// templateFields returns the names of the fields the template renders, e.g.
+// "team" for "team/{{.team}}". Fields within range and with blocks are
+// relative to another value, so they're left out.
+func templateFields(tmpl *template.Template) []string {
+	var fields []string
+	var walk func(parse.Node)
+	walk = func(node parse.Node) {
+		switch node := node.(type) {
+		case *parse.ListNode:
+			if node == nil {
+				return
+			}
+			for _, n := range node.Nodes {
+				walk(n)
+			}
+		case *parse.ActionNode:
+			walk(node.Pipe)
+		case *parse.PipeNode:
+			if node == nil {
+				return
+			}
+			for _, cmd := range node.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range node.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(node.Node)
+		case *parse.FieldNode:
+			if !contains(fields, node.Ident[0]) {
+				fields = append(fields, node.Ident[0])
+			}
+		case *parse.IfNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.RangeNode:
+			walk(node.Pipe)
+		case *parse.WithNode:
+			walk(node.Pipe)
+		}
+	}
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root)
+	}
+	return fields
+}
+
+// validateTemplates reports templated labels that render a field no regex of
+// their rule captures, e.g. "team/{{.tema}}" for a "(?P<team>...)" group.
+func (c labelerConfig) validateTemplates() error {
+	labels := make([]string, 0, len(c.Labels))
+	for label := range c.Labels {
+		if isLabelTemplate(label) {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		tmpl, err := template.New(label).Parse(label)
+		if err != nil {
+			return fmt.Errorf("failed to parse label template %q: %w", label, err)
+		}
+		subpatterns, err := c.Labels[label].captureSubpatterns()
+		if err != nil {
+			return fmt.Errorf("failed to parse rule regexes: %w", withConditionPath(label, err))
+		}
+		for _, field := range templateFields(tmpl) {
+			if _, ok := subpatterns[field]; !ok {
+				return fmt.Errorf("label %q renders {{.%s}}, but no regex of its rule captures %q", label, field, field)
+			}
+		}
+	}
+	return nil
+}
+
+// applyLabelTemplate removes every label the template could have rendered,
+// then adds the label rendered from captures when the conditions matched and
+// every capture the template renders is set.
+func applyLabelTemplate(labels []string, label string, subpatterns map[string]string, matched bool, captures map[string]string) ([]string, error) {
+	tmpl, err := template.New(label).Option("missingkey=error").Parse(label)
+	if err != nil {
+		return nil, err
+	}
+	stale, err := labelTemplateRegexp(label, subpatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for _, existing := range labels {
+		if !stale.MatchString(existing) {
+			labels[n] = existing
+			n++
+		}
+	}
+	labels = labels[:n]
+
+	if !matched {
+		return labels, nil
+	}
+	// An optional group that didn't participate in the match, or a group of
+	// an any block that matched through another branch, would render an
+	// incomplete label, such as "team/".
+	for _, field := range templateFields(tmpl) {
+		if captures[field] == "" {
+			return labels, nil
+		}
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, captures); err != nil {
+		return nil, err
+	}
+	if rendered.Len() == 0 {
+		return labels, nil
+	}
+	return addLabel(labels, rendered.String()), nil
+}
+
+// labelTemplateRegexp converts a templated label into a regexp that matches
+// any label the template renders. An action rendering a capture matches the
+// capture's subpattern, e.g. "team/{{.team}}" with "[a-z]+" becomes
+// "^team/(?:[a-z]+)$", while any other action matches ".+".
+func labelTemplateRegexp(label string, subpatterns map[string]string) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range templateActionRegexp.FindAllStringIndex(label, -1) {
+		pattern.WriteString(regexp.QuoteMeta(label[last:loc[0]]))
+		last = loc[1]
+		if field := templateFieldRegexp.FindStringSubmatch(label[loc[0]:loc[1]]); field != nil {
+			if sub, ok := subpatterns[field[1]]; ok {
+				pattern.WriteString("(?:" + sub + ")")
+				continue
+			}
+		}
+		pattern.WriteString(".+")
+	}
+	pattern.WriteString(regexp.QuoteMeta(label[last:]))
+	pattern.WriteString("$")
+	return regexp.Compile(pattern.String())
+}
@@ -0,0 +1,223 @@
+package main
+
+import "testing"
+
+func TestLabelTemplateRegexp(t *testing.T) {
+	subpatterns := map[string]string{"team": "[a-z]+", "major": `\d+`}
+
+	tests := []struct {
+		label    string
+		expected string
+	}{
+		{label: "team/{{.team}}", expected: `^team/(?:[a-z]+)$`},
+		{label: "v{{.major}}.{{.minor}}", expected: `^v(?:\d+)\..+$`},
+		{label: "{{ .area }} (area)", expected: `^.+ \(area\)$`},
+		{label: "{{.team | printf \"%s-team\"}}", expected: `^.+$`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.label, func(t *testing.T) {
+			actual, err := labelTemplateRegexp(tc.label, subpatterns)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if actual.String() != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, actual.String())
+			}
+		})
+	}
+}
+
+func TestCaptureSubpatterns(t *testing.T) {
+	conditions := labelConditions{
+		BranchName: "^(?:(?P<team>[a-z]+)/)?",
+		Any: []labelConditions{
+			{Title: `^\[(?P<team>[A-Z]+)\]`},
+			{BaseBranch: `^release/(?P<version>[0-9]+\.[0-9]+)$`},
+		},
+	}
+
+	actual, err := conditions.captureSubpatterns()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	expected := map[string]string{
+		"team":    "[A-Z]+|[a-z]+",
+		"version": `[0-9]+\.[0-9]+`,
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected: %v, got: %v", expected, actual)
+	}
+	for name, sub := range expected {
+		if actual[name] != sub {
+			t.Fatalf("expected %s: %q, got: %q", name, sub, actual[name])
+		}
+	}
+}
+
+func TestLabelsForPRStateTemplates(t *testing.T) {
+	config := labelerConfig{Labels: map[string]labelConditions{
+		"team/{{.team}}": {
+			BranchName: "^(?P<team>[a-z]+)/",
+		},
+		"v{{.version}}": {
+			BaseBranch: `^release/(?P<version>\d+\.\d+)$`,
+		},
+		"area/{{.area}}": {
+			Title: `^(?:(?P<area>[a-z]+): )?`,
+		},
+		"owner/{{.owner}}": {
+			Any: []labelConditions{
+				{BranchName: "^users/(?P<owner>[a-z]+)/"},
+				{Title: "^Fix"},
+			},
+		},
+		"Hotfix": {
+			BaseBranch: "^release/",
+			Title:      "^Hotfix",
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		state    prState
+		expected []string
+	}{
+		{
+			name: "Renders Captures",
+			state: prState{
+				labels:     []string{"Bug"},
+				branchName: "payments/fix-rounding",
+				baseBranch: "release/1.2",
+				title:      "Hotfix - Rounding",
+			},
+			expected: []string{"Bug", "team/payments", "v1.2", "Hotfix"},
+		},
+		{
+			name: "Replaces Stale Values",
+			state: prState{
+				labels:     []string{"team/payments", "v1.1", "Bug"},
+				branchName: "platform/faster-builds",
+				baseBranch: "release/1.2",
+			},
+			expected: []string{"Bug", "team/platform", "v1.2"},
+		},
+		{
+			name: "Removes When Unmatched",
+			state: prState{
+				labels:     []string{"team/payments", "v1.2"},
+				branchName: "Fix-Rounding",
+				baseBranch: "master",
+			},
+			expected: []string{},
+		},
+		{
+			name: "Keeps Labels The Capture Can't Render",
+			state: prState{
+				labels:     []string{"verified", "v1.x", "team/Payments"},
+				branchName: "Fix-Rounding",
+				baseBranch: "master",
+			},
+			expected: []string{"verified", "v1.x", "team/Payments"},
+		},
+		{
+			name: "Skips Empty Captures",
+			state: prState{
+				labels:     []string{"area/docs"},
+				branchName: "Fix-Rounding",
+				title:      "Fix rounding",
+			},
+			expected: []string{},
+		},
+		{
+			name: "Skips Captures Of Other Branches",
+			state: prState{
+				labels:     []string{"owner/octocat"},
+				branchName: "fix-rounding",
+				title:      "Fix stuff",
+			},
+			expected: []string{},
+		},
+		{
+			name: "Renders Captures Of Matching Branch",
+			state: prState{
+				branchName: "users/octocat/fix-rounding",
+				title:      "Add rounding",
+			},
+			expected: []string{"team/users", "owner/octocat"},
+		},
+		{
+			name: "Renders Optional Captures",
+			state: prState{
+				branchName: "Fix-Rounding",
+				title:      "billing: Fix rounding",
+			},
+			expected: []string{"area/billing"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := config.labelsForPRState(tc.state)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			assertStringSlicesEqualUnordered(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]labelConditions
+		invalid bool
+	}{
+		{
+			name: "Captured Fields",
+			labels: map[string]labelConditions{
+				"team/{{.team}}": {BranchName: "^(?P<team>[a-z]+)/"},
+				"v{{.major}}.{{.minor}}": {
+					All: []labelConditions{
+						{BaseBranch: `^release/(?P<major>\d+)\.`},
+						{BaseBranch: `\.(?P<minor>\d+)$`},
+					},
+				},
+				"Bug": {Title: "^Fix"},
+			},
+		},
+		{
+			name: "Misspelled Field",
+			labels: map[string]labelConditions{
+				"team/{{.tema}}": {BranchName: "^(?P<team>[a-z]+)/"},
+			},
+			invalid: true,
+		},
+		{
+			name: "Field In Pipeline",
+			labels: map[string]labelConditions{
+				`{{.area | printf "%s (area)"}}`: {Title: "^Fix"},
+			},
+			invalid: true,
+		},
+		{
+			name: "Unparsable Template",
+			labels: map[string]labelConditions{
+				"team/{{.team": {BranchName: "^(?P<team>[a-z]+)/"},
+			},
+			invalid: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := labelerConfig{Labels: tc.labels}.validateTemplates()
+			if tc.invalid && err == nil {
+				t.Fatalf("expected error, got: nil")
+			}
+			if !tc.invalid && err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import "github.com/MTIConnect/labeler-action/github"
+
+const (
+	waitingOnAuthor   = "author"
+	waitingOnReviewer = "reviewer"
+)
+
+// waitingOnRule applies exactly one of its labels, depending on whether the
+// author or the reviewers have to act next on the pull request.
+type waitingOnRule struct {
+	Author   string
+	Reviewer string
+}
+
+// apply replaces the rule's labels with the label of the party the pull
+// request is waiting on.
+func (r waitingOnRule) apply(labels []string, waitingOn string) []string {
+	labels = removeLabel(labels, r.Author)
+	labels = removeLabel(labels, r.Reviewer)
+
+	label := r.Reviewer
+	if waitingOn == waitingOnAuthor {
+		label = r.Author
+	}
+	if label != "" {
+		labels = addLabel(labels, label)
+	}
+	return labels
+}
+
+type timelineLister interface {
+	PullRequestTimeline(int) ([]github.TimelineEvent, error)
+}
+
+// waitingOn replays the timeline of a pull request to decide who has to act
+// next. Reviews and comments from anyone but the author hand the pull request
+// to the author, who either addresses the feedback or merges the approval.
+// Review requests, dismissed reviews, marking the pull request ready for
+// review and comments from the author hand it back to the reviewers. Commits
+// don't move it, authors re-request a review or comment once their fixes are
+// pushed. Drafts always wait on the author.
+func waitingOn(events []github.TimelineEvent, author string, draft bool) string {
+	if draft {
+		return waitingOnAuthor
+	}
+
+	waiting := waitingOnReviewer
+	for _, event := range events {
+		if event.IsBot {
+			continue
+		}
+		isAuthor := event.Actor == author
+
+		switch event.Event {
+		case "reviewed":
+			if isAuthor {
+				// Authors replying to review threads submit comment reviews.
+				waiting = waitingOnReviewer
+				continue
+			}
+			switch event.ReviewState {
+			case github.Approved, github.ChangesRequested, github.Commented:
+				waiting = waitingOnAuthor
+			}
+		case "commented":
+			if isAuthor {
+				waiting = waitingOnReviewer
+			} else {
+				waiting = waitingOnAuthor
+			}
+		case "review_requested", "review_dismissed", "ready_for_review":
+			waiting = waitingOnReviewer
+		}
+	}
+	return waiting
+}
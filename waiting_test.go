@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MTIConnect/labeler-action/github"
+)
+
+func TestWaitingOn(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []github.TimelineEvent
+		draft    bool
+		expected string
+	}{
+		{
+			name:     "New",
+			events:   []github.TimelineEvent{{Event: "committed"}},
+			expected: waitingOnReviewer,
+		},
+		{
+			name:     "Draft",
+			events:   []github.TimelineEvent{{Event: "review_requested", Actor: "author"}},
+			draft:    true,
+			expected: waitingOnAuthor,
+		},
+		{
+			name: "Changes Requested",
+			events: []github.TimelineEvent{
+				{Event: "review_requested", Actor: "author"},
+				{Event: "reviewed", Actor: "octocat", ReviewState: github.ChangesRequested},
+			},
+			expected: waitingOnAuthor,
+		},
+		{
+			name: "Fixes Pushed",
+			events: []github.TimelineEvent{
+				{Event: "reviewed", Actor: "octocat", ReviewState: github.ChangesRequested},
+				{Event: "committed"},
+			},
+			expected: waitingOnAuthor,
+		},
+		{
+			name: "Review Re-requested",
+			events: []github.TimelineEvent{
+				{Event: "reviewed", Actor: "octocat", ReviewState: github.ChangesRequested},
+				{Event: "committed"},
+				{Event: "review_requested", Actor: "author"},
+			},
+			expected: waitingOnReviewer,
+		},
+		{
+			name: "Author Replied",
+			events: []github.TimelineEvent{
+				{Event: "commented", Actor: "octocat"},
+				{Event: "reviewed", Actor: "author", ReviewState: github.Commented},
+			},
+			expected: waitingOnReviewer,
+		},
+		{
+			name: "Reviewer Commented",
+			events: []github.TimelineEvent{
+				{Event: "commented", Actor: "author"},
+				{Event: "commented", Actor: "octocat"},
+			},
+			expected: waitingOnAuthor,
+		},
+		{
+			name: "Bot Comment Ignored",
+			events: []github.TimelineEvent{
+				{Event: "review_requested", Actor: "author"},
+				{Event: "commented", Actor: "codecov[bot]", IsBot: true},
+			},
+			expected: waitingOnReviewer,
+		},
+		{
+			name: "Review Dismissed",
+			events: []github.TimelineEvent{
+				{Event: "reviewed", Actor: "octocat", ReviewState: github.ChangesRequested},
+				{Event: "review_dismissed", Actor: "maintainer"},
+			},
+			expected: waitingOnReviewer,
+		},
+		{
+			name: "Ready For Review",
+			events: []github.TimelineEvent{
+				{Event: "commented", Actor: "octocat"},
+				{Event: "ready_for_review", Actor: "author"},
+			},
+			expected: waitingOnReviewer,
+		},
+		{
+			name: "Approved",
+			events: []github.TimelineEvent{
+				{Event: "review_requested", Actor: "author"},
+				{Event: "reviewed", Actor: "octocat", ReviewState: github.Approved},
+			},
+			expected: waitingOnAuthor,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := waitingOn(tc.events, "author", tc.draft)
+			if actual != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWaitingOnRuleApply(t *testing.T) {
+	rule := waitingOnRule{Author: "waiting-on-author", Reviewer: "waiting-on-reviewer"}
+
+	labels := rule.apply([]string{"Bug", "waiting-on-reviewer"}, waitingOnAuthor)
+	assertStringSlicesEqual(t, []string{"Bug", "waiting-on-author"}, labels)
+
+	labels = rule.apply(labels, waitingOnReviewer)
+	assertStringSlicesEqual(t, []string{"Bug", "waiting-on-reviewer"}, labels)
+}